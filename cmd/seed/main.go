@@ -48,16 +48,26 @@ func main() {
 			Title:       "Maximum Array Sum",
 			Description: `Return the largest sum of any contiguous sub-array.`,
 			Difficulty:  "Medium",
-			CreatedAt:   now,
-			UpdatedAt:   now,
+			Signature: &models.FunctionSignature{
+				Name:       "maxSubArray",
+				Params:     []models.FunctionParam{{Name: "nums", Type: "int[]"}},
+				ReturnType: "int",
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
 		},
 		{
 			Title: "Container With Most Water",
 			Description: `Given heights of vertical lines, pick two lines that, along
 the x-axis, form a container with maximum area. Return that area.`,
 			Difficulty: "Medium",
-			CreatedAt:  now,
-			UpdatedAt:  now,
+			Signature: &models.FunctionSignature{
+				Name:       "maxArea",
+				Params:     []models.FunctionParam{{Name: "height", Type: "int[]"}},
+				ReturnType: "int",
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
 		},
 	}
 
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS execution_jobs (
+    id VARCHAR(36) PRIMARY KEY, -- UUID
+    language VARCHAR(50) NOT NULL,
+    code TEXT NOT NULL,
+    problem_id INTEGER REFERENCES problems(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued', -- queued, running, finished, failed, cancelled
+    success BOOLEAN NOT NULL DEFAULT false,
+    output TEXT,
+    error TEXT,
+    compile_error TEXT,
+    test_results JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_execution_jobs_status ON execution_jobs(status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE execution_jobs;
+-- +goose StatementEnd
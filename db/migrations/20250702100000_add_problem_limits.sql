@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS time_limit_ms INTEGER,   -- NULL = language default
+    ADD COLUMN IF NOT EXISTS memory_limit_mb INTEGER,
+    ADD COLUMN IF NOT EXISTS cpu_quota NUMERIC(4, 2);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS time_limit_ms,
+    DROP COLUMN IF EXISTS memory_limit_mb,
+    DROP COLUMN IF EXISTS cpu_quota;
+-- +goose StatementEnd
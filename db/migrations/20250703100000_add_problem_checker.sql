@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS checker JSONB; -- NULL = exact comparison
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS checker;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS signature JSONB; -- NULL = stdin/stdout problem
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS signature;
+-- +goose StatementEnd
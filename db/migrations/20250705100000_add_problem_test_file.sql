@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS test_file TEXT; -- Go _test.go source, hidden from candidates
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS test_file;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS files JSONB; -- scaffolding files, path -> content
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS files;
+-- +goose StatementEnd
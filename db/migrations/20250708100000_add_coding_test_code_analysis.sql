@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE coding_tests
+    ADD COLUMN IF NOT EXISTS code_analysis JSONB; -- NULL = submission not analyzed
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE coding_tests
+    DROP COLUMN IF EXISTS code_analysis;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS execution_result_cache (
+    key VARCHAR(64) PRIMARY KEY, -- SHA-256 of code, toolchain, limits and test cases
+    results JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_execution_result_cache_expires_at ON execution_result_cache(expires_at);
+CREATE INDEX IF NOT EXISTS idx_execution_result_cache_created_at ON execution_result_cache(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE execution_result_cache;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- No foreign keys: the audit trail outlives deleted problems, companies and
+-- tests. The executor checks test_id before it attributes a run.
+CREATE TABLE IF NOT EXISTS executions (
+    id BIGSERIAL PRIMARY KEY,
+    code_hash VARCHAR(64) NOT NULL, -- SHA-256 of the submitted code and files
+    language VARCHAR(50) NOT NULL,
+    version VARCHAR(20),
+    problem_id INTEGER,
+    company_id INTEGER, -- company whose API key requested the run
+    test_id VARCHAR(36), -- coding test the run belongs to
+    verdict VARCHAR(30), -- NULL when the execution failed
+    test_cases JSONB NOT NULL DEFAULT '[]', -- verdict and usage per test case
+    cached BOOLEAN NOT NULL DEFAULT false,
+    error TEXT,
+    duration_ms BIGINT NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_executions_company_id ON executions(company_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_executions_test_id ON executions(test_id, started_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE executions;
+-- +goose StatementEnd
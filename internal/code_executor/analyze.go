@@ -0,0 +1,156 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-code-runner/internal/models"
+)
+
+// ErrAnalysisUnsupported is returned when static analysis is requested for a
+// language without analyzers.
+var ErrAnalysisUnsupported = errors.New("static analysis unsupported")
+
+// Analyzer is a static check run over a submission without building or
+// running it, e.g. go vet.
+type Analyzer struct {
+	Name string `json:"name"`
+	// Cmd is run in the workspace. Every "file:line[:column]: message" line
+	// it prints becomes a warning, or an error if the line starts with the
+	// analyzer's name, as go vet reports type errors: "vet: main.go:3:8: ...".
+	Cmd string `json:"-"`
+	// FileMessage marks analyzers that only list offending files on stdout,
+	// like gofmt -l. Each listed file becomes a warning with this message.
+	FileMessage string `json:"-"`
+}
+
+// SetAnalyzers replaces the analyzers of a registered language, e.g. to add
+// the ones from the configuration.
+func (r *Registry) SetAnalyzers(name string, analyzers []Analyzer) error {
+	lang, err := r.Get(name)
+	if err != nil {
+		return err
+	}
+	for _, analyzer := range analyzers {
+		if analyzer.Name == "" || analyzer.Cmd == "" {
+			return fmt.Errorf("analyzer for %s needs a name and a command", name)
+		}
+	}
+	lang.Analyzers = analyzers
+	return nil
+}
+
+// Analyze runs the language's analyzers over the submission in the sandbox
+// and returns their findings, an empty slice when there are none. Options
+// select files, version and tenant as for Execute.
+func (s *service) Analyze(ctx context.Context, code string, language string, opts ...Option) ([]models.Diagnostic, error) {
+	o := newExecOptions(opts)
+
+	lang, err := s.resolveLanguage(language, o)
+	if err != nil {
+		return nil, err
+	}
+	if len(lang.Analyzers) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrAnalysisUnsupported, lang.Name)
+	}
+
+	var files map[string]string
+	code, lang, files, err = s.prepareFiles(code, lang, o.files)
+	if errors.Is(err, ErrDisallowedImport) {
+		return []models.Diagnostic{{Severity: "error", Message: err.Error()}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sandbox.Prepare(ctx, lang); err != nil {
+		return nil, err
+	}
+
+	ws, err := s.newWorkspace(code, lang, files)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.remove()
+
+	// Like the compiler, analyzers get the language limits and the full
+	// execution timeout rather than the problem's.
+	limits := lang.Limits
+	limits.TimeLimit = s.executionTimeout
+
+	diagnostics := []models.Diagnostic{}
+	for _, analyzer := range lang.Analyzers {
+		found, err := s.runAnalyzer(ctx, ws, lang, limits, analyzer)
+		if err != nil {
+			return nil, err
+		}
+		diagnostics = append(diagnostics, found...)
+	}
+	return diagnostics, nil
+}
+
+// runAnalyzer runs a single analyzer and turns its output into diagnostics.
+// An analyzer that fails without pointing at the code, e.g. because it is
+// missing from the image, is reported as an error diagnostic.
+func (s *service) runAnalyzer(ctx context.Context, ws *workspace, lang *Language, limits Limits, analyzer Analyzer) ([]models.Diagnostic, error) {
+	s.logger.Printf("[%s] Running analyzer %s...", ws.runID, analyzer.Name)
+	result, err := s.runSandbox(ctx, ws, RunRequest{Lang: lang, Limits: limits, Script: analyzer.Cmd, WritableCaches: true}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("analyzer %s failed: %w", analyzer.Name, err)
+	}
+
+	var found []models.Diagnostic
+	switch {
+	case result.TimedOut || result.OOMKilled:
+		found = []models.Diagnostic{{Severity: "error", Message: toolFailure(result, limits)}}
+	case analyzer.FileMessage != "":
+		for _, file := range strings.Split(ws.relativize(result.Output), "\n") {
+			if file = strings.TrimSpace(file); file != "" {
+				found = append(found, models.Diagnostic{
+					File:     strings.TrimPrefix(file, "./"),
+					Severity: "warning",
+					Message:  analyzer.FileMessage,
+				})
+			}
+		}
+		// Files it cannot parse are reported on stderr.
+		found = append(found, parseDiagnostics(ws.relativize(result.Error), "error")...)
+	default:
+		// Lines prefixed with the analyzer's name are code it could not
+		// check, e.g. type errors, rather than findings.
+		var findings, failures []string
+		for _, line := range strings.Split(ws.relativize(result.Output+"\n"+result.Error), "\n") {
+			if failure, ok := strings.CutPrefix(line, analyzer.Name+": "); ok {
+				failures = append(failures, failure)
+			} else {
+				findings = append(findings, line)
+			}
+		}
+		found = append(parseDiagnostics(strings.Join(findings, "\n"), "warning"),
+			parseDiagnostics(strings.Join(failures, "\n"), "error")...)
+	}
+
+	if len(found) == 0 && result.ExitCode != 0 {
+		message := strings.TrimSpace(ws.relativize(result.Output + "\n" + result.Error))
+		if message == "" {
+			message = fmt.Sprintf("exit status %d", result.ExitCode)
+		}
+		found = []models.Diagnostic{{Severity: "error", Message: message}}
+	}
+
+	for i := range found {
+		found[i].Source = analyzer.Name
+	}
+	return found, nil
+}
+
+// toolFailure describes how a tool run over the submission, such as an
+// analyzer or formatter, ran out of its limits.
+func toolFailure(result *ExecutionResult, limits Limits) string {
+	if result.TimedOut {
+		return fmt.Sprintf("timed out after %v", limits.TimeLimit)
+	}
+	return fmt.Sprintf("exceeded the memory limit (%d MB)", limits.MemoryMB)
+}
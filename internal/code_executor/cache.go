@@ -0,0 +1,271 @@
+package code_executor
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"go-code-runner/internal/models"
+)
+
+const (
+	defaultResultCacheTTL        = 10 * time.Minute
+	defaultResultCacheMaxEntries = 1000
+	// resultStorePruneInterval spaces out the pruning of the result store.
+	resultStorePruneInterval = time.Minute
+)
+
+// ResultCache holds the results of ExecuteWithTestCases by resultKey, so
+// rerunning unchanged code skips the sandbox. It is best-effort: failures
+// are logged and treated as misses. Implementations must be safe for
+// concurrent use.
+type ResultCache interface {
+	Get(ctx context.Context, key string) (*models.ExecutionResults, bool)
+	Put(ctx context.Context, key string, results *models.ExecutionResults)
+}
+
+// ResultStore persists cached results outside the process, e.g. in
+// Postgres, so they survive restarts and are shared between instances.
+type ResultStore interface {
+	// GetCachedResult returns nil results when key is missing or expired.
+	GetCachedResult(ctx context.Context, key string) (*models.ExecutionResults, time.Time, error)
+	PutCachedResult(ctx context.Context, key string, results *models.ExecutionResults, expiresAt time.Time) error
+	// PruneCachedResults deletes expired entries and the oldest entries
+	// beyond maxEntries.
+	PruneCachedResults(ctx context.Context, maxEntries int) error
+}
+
+// ResultCacheConfig configures NewResultCache.
+type ResultCacheConfig struct {
+	// TTL is how long results are served from the cache. Defaults to 10
+	// minutes.
+	TTL time.Duration
+	// MaxEntries bounds the cache, evicting the least recently used
+	// results first. Defaults to 1000.
+	MaxEntries int
+	// Store optionally backs the in-memory cache; nil keeps results in
+	// memory only.
+	Store ResultStore
+}
+
+type cachedResult struct {
+	key       string
+	results   *models.ExecutionResults
+	expiresAt time.Time
+}
+
+// resultCache is an in-memory LRU cache in front of an optional store.
+type resultCache struct {
+	ttl        time.Duration
+	maxEntries int
+	store      ResultStore
+	logger     *log.Logger
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List // most recently used first
+	lastPruned time.Time
+}
+
+func NewResultCache(cfg ResultCacheConfig, logger *log.Logger) ResultCache {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultResultCacheTTL
+	}
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultResultCacheMaxEntries
+	}
+
+	return &resultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		store:      cfg.Store,
+		logger:     logger,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (c *resultCache) Get(ctx context.Context, key string) (*models.ExecutionResults, bool) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cachedResult)
+		if time.Now().Before(entry.expiresAt) {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			return copyResults(entry.results), true
+		}
+		c.remove(elem)
+	}
+	c.mu.Unlock()
+
+	if c.store == nil {
+		return nil, false
+	}
+	results, expiresAt, err := c.store.GetCachedResult(ctx, key)
+	if err != nil {
+		c.logger.Printf("Failed to read cached result %s: %v", key, err)
+		return nil, false
+	}
+	if results == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	c.add(key, results, expiresAt)
+	c.mu.Unlock()
+	return copyResults(results), true
+}
+
+func (c *resultCache) Put(ctx context.Context, key string, results *models.ExecutionResults) {
+	results = copyResults(results)
+	results.Cached = false
+	expiresAt := time.Now().Add(c.ttl)
+
+	c.mu.Lock()
+	c.add(key, results, expiresAt)
+	prune := c.store != nil && time.Since(c.lastPruned) >= resultStorePruneInterval
+	if prune {
+		c.lastPruned = time.Now()
+	}
+	c.mu.Unlock()
+
+	if c.store == nil {
+		return
+	}
+	if err := c.store.PutCachedResult(ctx, key, results, expiresAt); err != nil {
+		c.logger.Printf("Failed to store cached result %s: %v", key, err)
+	}
+	if prune {
+		if err := c.store.PruneCachedResults(ctx, c.maxEntries); err != nil {
+			c.logger.Printf("Failed to prune cached results: %v", err)
+		}
+	}
+}
+
+// add inserts or replaces an entry and evicts the least recently used ones
+// beyond maxEntries. c.mu must be held.
+func (c *resultCache) add(key string, results *models.ExecutionResults, expiresAt time.Time) {
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(&cachedResult{key: key, results: results, expiresAt: expiresAt})
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *resultCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cachedResult).key)
+}
+
+// copyResults deep-copies results so that callers may change anything in
+// them without touching the cached entry.
+func copyResults(results *models.ExecutionResults) *models.ExecutionResults {
+	c := *results
+	c.Diagnostics = copyDiagnostics(results.Diagnostics)
+	if results.TestResults != nil {
+		c.TestResults = make([]models.TestResult, len(results.TestResults))
+		for i, testResult := range results.TestResults {
+			testResult.Diagnostics = copyDiagnostics(testResult.Diagnostics)
+			if testResult.Usage != nil {
+				usage := *testResult.Usage
+				testResult.Usage = &usage
+			}
+			c.TestResults[i] = testResult
+		}
+	}
+	return &c
+}
+
+func copyDiagnostics(diagnostics []models.Diagnostic) []models.Diagnostic {
+	if diagnostics == nil {
+		return nil
+	}
+	c := make([]models.Diagnostic, len(diagnostics))
+	for i, diagnostic := range diagnostics {
+		diagnostic.Stack = append([]models.StackFrame(nil), diagnostic.Stack...)
+		c[i] = diagnostic
+	}
+	return c
+}
+
+// resultKey hashes everything that determines the results of running a
+// submission against test cases: the code and files as built, the
+// toolchain image, sandbox runtime and commands, the limits, the checker
+// and the test cases.
+func resultKey(code string, lang *Language, files map[string]string, limits Limits, maxOutputBytes int, checker *models.Checker, testCases []*models.TestCase) string {
+	type testCaseKey struct {
+		ID             int    `json:"id"`
+		Input          string `json:"input"`
+		ExpectedOutput string `json:"expected_output"`
+		IsHidden       bool   `json:"is_hidden"`
+	}
+	key := struct {
+		Version        int               `json:"version"`
+		Language       string            `json:"language"`
+		Image          string            `json:"image"`
+		Runtime        string            `json:"runtime"`
+		CompileCmd     string            `json:"compile_cmd"`
+		RunCmd         string            `json:"run_cmd"`
+		Env            []string          `json:"env"`
+		Code           string            `json:"code"`
+		Files          map[string]string `json:"files"`
+		TimeLimitNs    time.Duration     `json:"time_limit_ns"`
+		MemoryMB       int               `json:"memory_mb"`
+		CPUs           float64           `json:"cpus"`
+		MaxOutputBytes int               `json:"max_output_bytes"`
+		Checker        *models.Checker   `json:"checker"`
+		TestCases      []testCaseKey     `json:"test_cases"`
+	}{
+		// Version changes whenever cached results would be judged
+		// differently, invalidating older entries in a shared store.
+		Version:        1,
+		Language:       lang.Name,
+		Image:          lang.Image,
+		Runtime:        lang.Runtime,
+		CompileCmd:     lang.CompileCmd,
+		RunCmd:         lang.RunCmd,
+		Env:            lang.Env,
+		Code:           code,
+		Files:          files,
+		TimeLimitNs:    limits.TimeLimit,
+		MemoryMB:       limits.MemoryMB,
+		CPUs:           limits.CPUs,
+		MaxOutputBytes: maxOutputBytes,
+		Checker:        checker,
+	}
+	for _, testCase := range testCases {
+		key.TestCases = append(key.TestCases, testCaseKey{
+			ID:             testCase.ID,
+			Input:          testCase.Input,
+			ExpectedOutput: testCase.ExpectedOutput,
+			IsHidden:       testCase.IsHidden,
+		})
+	}
+
+	// Marshalling cannot fail for these types; maps are encoded sorted.
+	b, _ := json.Marshal(key)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheable reports whether results only depend on the submission, i.e.
+// no test case failed for reasons of the infrastructure or hit a time or
+// memory limit, which depends on the load of the host.
+func cacheable(results *models.ExecutionResults) bool {
+	for _, testResult := range results.TestResults {
+		switch testResult.Verdict {
+		case models.VerdictInternalError, models.VerdictTimeLimitExceeded, models.VerdictMemoryLimitExceeded:
+			return false
+		}
+	}
+	return true
+}
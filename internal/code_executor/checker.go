@@ -0,0 +1,237 @@
+package code_executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-code-runner/internal/models"
+)
+
+// defaultFloatEpsilon is used by the float checker when a problem sets
+// neither an absolute nor a relative epsilon.
+const defaultFloatEpsilon = 1e-6
+
+// Checker exit codes, following the usual special judge convention.
+const (
+	checkerExitAccepted    = 0
+	checkerExitWrongAnswer = 1
+)
+
+// judge decides whether the output of a run is correct. Custom checkers are
+// compiled once into their own workspace, so the candidate's program never
+// sees the checker source.
+type judge struct {
+	s       *service
+	checker models.Checker
+	lang    *Language
+	ws      *workspace
+}
+
+func (s *service) newJudge(ctx context.Context, checker *models.Checker) (*judge, error) {
+	j := &judge{s: s, checker: models.Checker{Type: models.CheckerExact}}
+	if checker == nil {
+		return j, nil
+	}
+	j.checker = *checker
+
+	switch checker.Type {
+	case models.CheckerExact, models.CheckerTokens, models.CheckerFloat, models.CheckerUnordered, models.CheckerJSON:
+		return j, nil
+	case models.CheckerCustom:
+	default:
+		return nil, fmt.Errorf("unknown checker type %q", checker.Type)
+	}
+
+	lang, err := s.languages.Get(checker.Language)
+	if err != nil {
+		return nil, fmt.Errorf("checker: %w", err)
+	}
+	if err := s.sandbox.Prepare(ctx, lang); err != nil {
+		return nil, fmt.Errorf("checker: %w", err)
+	}
+
+	ws, err := s.newWorkspace(checker.Code, lang, nil)
+	if err != nil {
+		return nil, fmt.Errorf("checker: %w", err)
+	}
+
+	compileError, err := s.compile(ctx, ws, lang)
+	if err != nil {
+		ws.remove()
+		return nil, fmt.Errorf("checker: %w", err)
+	}
+	if compileError != "" {
+		ws.remove()
+		return nil, fmt.Errorf("checker failed to compile: %s", compileError)
+	}
+
+	j.lang = lang
+	j.ws = ws
+	return j, nil
+}
+
+func (j *judge) close() {
+	if j.ws != nil {
+		j.ws.remove()
+	}
+}
+
+// check returns the verdict for one accepted run together with an optional
+// message explaining a rejection.
+func (j *judge) check(ctx context.Context, name string, testCase *models.TestCase, output string) (models.Verdict, string, error) {
+	var (
+		ok      bool
+		message string
+	)
+
+	switch j.checker.Type {
+	case models.CheckerTokens:
+		ok, message = compareTokens(testCase.ExpectedOutput, output, func(expected, actual string) bool {
+			return expected == actual
+		})
+	case models.CheckerFloat:
+		absEpsilon, relEpsilon := j.checker.AbsEpsilon, j.checker.RelEpsilon
+		if absEpsilon == 0 && relEpsilon == 0 {
+			absEpsilon = defaultFloatEpsilon
+		}
+		ok, message = compareTokens(testCase.ExpectedOutput, output, func(expected, actual string) bool {
+			return floatsMatch(expected, actual, absEpsilon, relEpsilon)
+		})
+	case models.CheckerUnordered:
+		ok, message = compareUnordered(testCase.ExpectedOutput, output)
+	case models.CheckerJSON:
+		ok, message = compareJSON(testCase.ExpectedOutput, output)
+	case models.CheckerCustom:
+		return j.runChecker(ctx, name, testCase, output)
+	default:
+		ok = strings.TrimSpace(output) == strings.TrimSpace(testCase.ExpectedOutput)
+	}
+
+	if !ok {
+		return models.VerdictWrongAnswer, message, nil
+	}
+	return models.VerdictAccepted, "", nil
+}
+
+// runChecker runs the custom checker program with the input, expected and
+// actual output of one test case.
+func (j *judge) runChecker(ctx context.Context, name string, testCase *models.TestCase, output string) (models.Verdict, string, error) {
+	files := make([]string, 0, 3)
+	for _, f := range []struct{ kind, content string }{
+		{"input", testCase.Input},
+		{"expected", testCase.ExpectedOutput},
+		{"actual", output},
+	} {
+		fileName, err := j.ws.writeFile(f.kind, name, f.content)
+		if err != nil {
+			return "", "", fmt.Errorf("checker: %w", err)
+		}
+		files = append(files, fileName)
+	}
+
+	limits := j.lang.Limits
+	limits.TimeLimit = j.s.executionTimeout
+
+	script := j.lang.RunCmd + " " + strings.Join(files, " ")
+	result, err := j.s.runSandbox(ctx, j.ws, RunRequest{Lang: j.lang, Limits: limits, Script: script}, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("checker: %w", err)
+	}
+	if result.TimedOut || result.OOMKilled {
+		return "", "", fmt.Errorf("checker %s", toolFailure(result, limits))
+	}
+
+	message := strings.TrimSpace(result.Output)
+	switch result.ExitCode {
+	case checkerExitAccepted:
+		return models.VerdictAccepted, message, nil
+	case checkerExitWrongAnswer:
+		return models.VerdictWrongAnswer, message, nil
+	default:
+		return "", "", fmt.Errorf("checker exited with code %d: %s", result.ExitCode, strings.TrimSpace(result.Error))
+	}
+}
+
+// compareTokens compares whitespace-separated tokens pairwise.
+func compareTokens(expectedOutput, actualOutput string, match func(expected, actual string) bool) (bool, string) {
+	expected := strings.Fields(expectedOutput)
+	actual := strings.Fields(actualOutput)
+
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		if !match(expected[i], actual[i]) {
+			return false, fmt.Sprintf("token %d: expected %q, got %q", i+1, expected[i], actual[i])
+		}
+	}
+	if len(expected) != len(actual) {
+		return false, fmt.Sprintf("expected %d tokens, got %d", len(expected), len(actual))
+	}
+	return true, ""
+}
+
+// floatsMatch compares two tokens as numbers when both parse as floats and
+// as strings otherwise. NaN only matches NaN and an infinity only the
+// infinity of the same sign, as no epsilon brings them closer.
+func floatsMatch(expected, actual string, absEpsilon, relEpsilon float64) bool {
+	e, errE := strconv.ParseFloat(expected, 64)
+	a, errA := strconv.ParseFloat(actual, 64)
+	if errE != nil || errA != nil {
+		return expected == actual
+	}
+
+	switch {
+	case math.IsNaN(e) || math.IsNaN(a):
+		return math.IsNaN(e) && math.IsNaN(a)
+	case math.IsInf(e, 0) || math.IsInf(a, 0):
+		return e == a
+	}
+
+	diff := math.Abs(e - a)
+	return diff <= absEpsilon || diff <= relEpsilon*math.Abs(e)
+}
+
+// compareUnordered compares the non-empty, trimmed lines of both outputs as
+// multisets.
+func compareUnordered(expectedOutput, actualOutput string) (bool, string) {
+	expected := sortedLines(expectedOutput)
+	actual := sortedLines(actualOutput)
+
+	if len(expected) != len(actual) {
+		return false, fmt.Sprintf("expected %d lines, got %d", len(expected), len(actual))
+	}
+	for i := range expected {
+		if expected[i] != actual[i] {
+			return false, fmt.Sprintf("first differing line in sorted order: expected %q, got %q", expected[i], actual[i])
+		}
+	}
+	return true, ""
+}
+
+// compareJSON compares both outputs as JSON values, falling back to an exact
+// comparison when the expected output is not JSON.
+func compareJSON(expectedOutput, actualOutput string) (bool, string) {
+	var expected, actual any
+	if err := json.Unmarshal([]byte(expectedOutput), &expected); err != nil {
+		return strings.TrimSpace(expectedOutput) == strings.TrimSpace(actualOutput), ""
+	}
+	if err := json.Unmarshal([]byte(actualOutput), &actual); err != nil {
+		return false, "output is not valid JSON"
+	}
+	return reflect.DeepEqual(expected, actual), ""
+}
+
+func sortedLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
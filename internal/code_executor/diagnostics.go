@@ -0,0 +1,122 @@
+package code_executor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go-code-runner/internal/models"
+)
+
+var (
+	// compilerMessage matches "file:line[:column]: [severity: ]message" as
+	// printed by the Go compiler, gcc and javac.
+	compilerMessage = regexp.MustCompile(`^(\S+?\.\w+):(\d+)(?::(\d+))?: (?:(error|warning|note|fatal error): )?(.*)$`)
+	// goStackFile matches the location line below a function in a Go stack
+	// trace, e.g. "\t/app/main.go:8 +0x1d".
+	goStackFile = regexp.MustCompile(`^\t(.+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	// containerPath matches the workspace mount of the Docker sandbox at the
+	// start of a path.
+	containerPath = regexp.MustCompile(`(^|[\s"'(=])/app/`)
+)
+
+// relativize strips the workspace location from paths in compiler and
+// runtime output, e.g. "/app/main.go" becomes "main.go".
+func (w *workspace) relativize(output string) string {
+	output = strings.ReplaceAll(output, w.dir+"/", "")
+	return containerPath.ReplaceAllString(output, "$1")
+}
+
+// parseCompileDiagnostics extracts the located messages from compiler
+// output. Lines that name no file, e.g. "# command-line-arguments", are
+// skipped.
+func parseCompileDiagnostics(output string) []models.Diagnostic {
+	return parseDiagnostics(output, "error")
+}
+
+// parseDiagnostics extracts "file:line[:column]: message" lines, giving
+// messages without a severity of their own defaultSeverity.
+func parseDiagnostics(output string, defaultSeverity string) []models.Diagnostic {
+	var diagnostics []models.Diagnostic
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		m := compilerMessage.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+
+		d := models.Diagnostic{
+			File:     strings.TrimPrefix(m[1], "./"),
+			Severity: diagnosticSeverity(m[4], defaultSeverity),
+			Message:  m[5],
+		}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		// javac points at the column with a caret below the source line.
+		if d.Column == 0 && i+2 < len(lines) && strings.TrimSpace(lines[i+2]) == "^" {
+			d.Column = strings.Index(lines[i+2], "^") + 1
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+func diagnosticSeverity(severity string, defaultSeverity string) string {
+	switch severity {
+	case "":
+		return defaultSeverity
+	case "warning":
+		return "warning"
+	case "note":
+		return "info"
+	}
+	return "error"
+}
+
+// parseGoPanic turns the panic or fatal error report of a Go program into a
+// diagnostic located at the innermost frame in the submission, with the
+// stack of the goroutine that crashed. It returns nil if stderr holds none.
+func parseGoPanic(stderr string) *models.Diagnostic {
+	lines := strings.Split(stderr, "\n")
+
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+	d := &models.Diagnostic{Severity: "error", Message: strings.TrimSpace(lines[start])}
+
+	i := start + 1
+	for i < len(lines) && !strings.HasPrefix(lines[i], "goroutine ") {
+		i++
+	}
+	// The crashed goroutine's frames run up to the next blank line, each a
+	// function line followed by its location.
+	for i++; i+1 < len(lines) && lines[i] != ""; i += 2 {
+		m := goStackFile.FindStringSubmatch(lines[i+1])
+		if m == nil {
+			break
+		}
+		function := strings.TrimPrefix(lines[i], "created by ")
+		function, _, _ = strings.Cut(function, " in goroutine ")
+		if paren := strings.LastIndex(function, "("); paren > 0 {
+			function = function[:paren]
+		}
+		line, _ := strconv.Atoi(m[2])
+		d.Stack = append(d.Stack, models.StackFrame{Function: function, File: m[1], Line: line})
+	}
+
+	for _, frame := range d.Stack {
+		if !strings.HasPrefix(frame.File, "/") {
+			d.File = frame.File
+			d.Line = frame.Line
+			break
+		}
+	}
+	return d
+}
@@ -3,258 +3,638 @@ package code_executor
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"go-code-runner/internal/models"
+	"go-code-runner/internal/platform/docker"
+	problemrepo "go-code-runner/internal/repository/problems"
 	testcaserepo "go-code-runner/internal/repository/test_cases"
 )
 
 type ExecutionResult struct {
-	Output string
-	Error  string
+	Output       string
+	Error        string
+	ExitCode     int
+	OOMKilled    bool
+	TimedOut     bool
+	Truncated    bool
+	CompileError string
+	Verdict      models.Verdict
+	Diagnostics  []models.Diagnostic
+	Usage        *models.ResourceUsage
 }
 
-type service struct {
-	executionTimeout time.Duration
-	logger           *log.Logger
-	imageCache       map[string]bool
-	repository       testcaserepo.TestCaseRepository
-
-	buildCacheDir string
-	modCacheDir   string
-	hostTempDir   string
+const apiContainerBaseDir = "/tmp/runbox"
+
+// Config holds the process-wide executor settings.
+type Config struct {
+	ExecutionTimeout time.Duration
+	// MaxConcurrentSandboxes caps the sandboxes running at once across all
+	// requests; anything over the cap waits for a free slot.
+	MaxConcurrentSandboxes int
+	// MaxParallelTestCases caps how many test cases of one submission run
+	// at the same time.
+	MaxParallelTestCases int
+	// MaxSubmissionBytes caps the total size of a multi-file submission.
+	MaxSubmissionBytes int
+	// MaxOutputBytes caps stdout and stderr of every run separately. A run
+	// that writes more is stopped with VerdictOutputLimitExceeded.
+	MaxOutputBytes int
+	// AllowedModules are the third-party modules submissions may import.
+	// They must be present in the shared module cache, see SeedGoModules.
+	AllowedModules []Module
+	// Sandbox runs the commands of a submission. Defaults to Docker.
+	Sandbox Sandbox
+	// TenantRuntimes maps company IDs to the runtime their submissions must
+	// run under, overriding the language's, see WithTenant.
+	TenantRuntimes map[int]string
+	// ResultCache serves repeated ExecuteWithTestCases calls with unchanged
+	// inputs without running them again. Nil disables caching.
+	ResultCache ResultCache
+	// History records every execution, including those rejected for an
+	// unknown language or toolchain version. Nil disables the history.
+	History ExecutionHistory
+	// CodingTests verifies the coding tests executions are attributed to
+	// with WithCodingTest. Nil leaves executions unattributed.
+	CodingTests CodingTests
 }
 
-func NewService(timeout time.Duration, logger *log.Logger, repo testcaserepo.TestCaseRepository) Service {
-	buildCacheDir := "/tmp/runbox/go-build-cache"
-	modCacheDir := "/tmp/runbox/go-mod-cache"
-
-	os.MkdirAll(buildCacheDir, 0755)
-	os.MkdirAll(modCacheDir, 0755)
+type service struct {
+	executionTimeout     time.Duration
+	maxParallelTestCases int
+	maxSubmissionBytes   int
+	maxOutputBytes       int
+	allowedModules       []Module
+	logger               *log.Logger
+	repository           testcaserepo.TestCaseRepository
+	problems             problemrepo.ProblemRepository
+	languages            *Registry
+	sandboxes            *sandboxPool
+	sandbox              Sandbox
+	tenantRuntimes       map[int]string
+	resultCache          ResultCache
+	history              ExecutionHistory
+	codingTests          CodingTests
+}
 
-	hostTempDir := os.Getenv("HOST_TEMP_DIR") // TODO: Throw error if ENV is not set for HOST_TEMP_DIR
-	if hostTempDir == "" {
-		hostTempDir = "/tmp/runbox"
+func NewService(cfg Config, languages *Registry, logger *log.Logger, repo testcaserepo.TestCaseRepository, problems problemrepo.ProblemRepository) Service {
+	sandbox := cfg.Sandbox
+	if sandbox == nil {
+		sandbox = NewDockerSandbox(docker.NewClient(docker.DefaultHost), DockerSandboxConfig{}, logger)
 	}
 
-	return &service{
-		executionTimeout: timeout,
-		logger:           logger,
-		imageCache:       make(map[string]bool),
-		repository:       repo,
-		buildCacheDir:    buildCacheDir,
-		modCacheDir:      modCacheDir,
-		hostTempDir:      hostTempDir,
+	maxParallelTestCases := cfg.MaxParallelTestCases
+	if maxParallelTestCases < 1 {
+		maxParallelTestCases = 1
 	}
-}
 
-func (s *service) ensureDockerImageAvailable(imageName string) {
-	if _, exists := s.imageCache[imageName]; exists {
-		return
+	maxSubmissionBytes := cfg.MaxSubmissionBytes
+	if maxSubmissionBytes <= 0 {
+		maxSubmissionBytes = defaultMaxSubmissionBytes
 	}
 
-	s.logger.Printf("Checking if Docker image %s is available locally...", imageName)
+	maxOutputBytes := cfg.MaxOutputBytes
+	if maxOutputBytes <= 0 {
+		maxOutputBytes = defaultMaxOutputBytes
+	}
 
-	checkCmd := exec.Command("docker", "image", "inspect", imageName)
-	if err := checkCmd.Run(); err != nil {
-		s.logger.Printf("Docker image %s not found locally, pulling...", imageName)
-		pullCmd := exec.Command("docker", "pull", imageName)
-		pullCmd.Stdout = os.Stdout
-		pullCmd.Stderr = os.Stderr
-		if err := pullCmd.Run(); err != nil {
-			s.logger.Printf("Failed to pull Docker image %s: %v", imageName, err)
-		} else {
-			s.logger.Printf("Docker image %s pulled successfully", imageName)
-		}
-	} else {
-		s.logger.Printf("Docker image %s is already available locally", imageName)
+	return &service{
+		executionTimeout:     cfg.ExecutionTimeout,
+		maxParallelTestCases: maxParallelTestCases,
+		maxSubmissionBytes:   maxSubmissionBytes,
+		maxOutputBytes:       maxOutputBytes,
+		allowedModules:       cfg.AllowedModules,
+		logger:               logger,
+		repository:           repo,
+		problems:             problems,
+		languages:            languages,
+		sandboxes:            newSandboxPool(cfg.MaxConcurrentSandboxes),
+		sandbox:              sandbox,
+		tenantRuntimes:       cfg.TenantRuntimes,
+		resultCache:          cfg.ResultCache,
+		history:              cfg.History,
+		codingTests:          cfg.CodingTests,
 	}
+}
 
-	s.imageCache[imageName] = true
+func (s *service) Languages() []Language {
+	return s.languages.List()
 }
 
-func (s *service) executeCode(ctx context.Context, code string, language string, input string) (*ExecutionResult, error) {
-	runID := uuid.New().String()
-	s.logger.Printf("[%s] Creating temp directory...", runID)
-	dirStart := time.Now()
+func (s *service) Language(name string) (*Language, error) {
+	return s.languages.Get(name)
+}
 
-	apiContainerBaseDir := "/tmp/runbox"
-	if err := os.MkdirAll(apiContainerBaseDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create base temp dir: %w", err)
+// resolveLanguage looks the language up and pins the toolchain version
+// chosen with WithVersion, or the default one, and the runtime required by
+// the tenant.
+func (s *service) resolveLanguage(name string, o *execOptions) (*Language, error) {
+	lang, err := s.languages.Get(name)
+	if err != nil {
+		return nil, err
 	}
+	lang, err = lang.WithVersion(o.version)
+	if err != nil {
+		return nil, err
+	}
+	return lang.WithRuntime(s.tenantRuntimes[o.tenant]), nil
+}
 
-	apiContainerTempDir := filepath.Join(apiContainerBaseDir, "runbox-"+runID)
-	if err := os.MkdirAll(apiContainerTempDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+// runSandbox runs req in the workspace once a sandbox slot is free. The
+// workspace and output streams of req are filled in here.
+func (s *service) runSandbox(ctx context.Context, ws *workspace, req RunRequest, out *outputStream) (*ExecutionResult, error) {
+	if !s.sandboxes.tryAcquire() {
+		s.logger.Printf("[%s] All sandbox slots busy, queueing...", ws.runID)
+		queueStart := time.Now()
+		if err := s.sandboxes.acquire(ctx); err != nil {
+			return nil, fmt.Errorf("waiting for a free sandbox: %w", err)
+		}
+		s.logger.Printf("[%s] Sandbox slot acquired. (waited %v)", ws.runID, time.Since(queueStart))
 	}
-	defer os.RemoveAll(apiContainerTempDir)
-	s.logger.Printf("[%s] Temp directory created at %s. (took %v)", runID, apiContainerTempDir, time.Since(dirStart))
+	defer s.sandboxes.release()
 
-	s.logger.Printf("[%s] Writing code to file...", runID)
-	writeStart := time.Now()
+	// Output over the limit stops the run rather than piling up in memory
+	// until the time limit.
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
 
-	codeFileName := "main.go"
-	codePath := filepath.Join(apiContainerTempDir, codeFileName)
-	if err := os.WriteFile(codePath, []byte(code), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write code to file: %w", err)
+	var stdout, stderr bytes.Buffer
+	stdoutLimit := newLimitWriter(out.writer(StreamStdout, &stdout), s.maxOutputBytes, stop)
+	stderrLimit := newLimitWriter(out.writer(StreamStderr, &stderr), s.maxOutputBytes, stop)
+	runStart := time.Now()
+
+	req.RunID = ws.runID
+	req.Dir = ws.dir
+	req.Stdout = stdoutLimit
+	req.Stderr = stderrLimit
+	res, err := s.sandbox.Run(runCtx, req)
+	if ctx.Err() == context.Canceled {
+		return nil, fmt.Errorf("execution cancelled: %w", ctx.Err())
 	}
-	s.logger.Printf("[%s] Code written to %s. (took %v)", runID, codePath, time.Since(writeStart))
-
-	inputFile := ""
-	if input != "" {
-		inputFile = filepath.Join(apiContainerTempDir, "input.txt")
-		if err := os.WriteFile(inputFile, []byte(input), 0644); err != nil {
-			return nil, fmt.Errorf("failed to write input to file: %w", err)
+	truncated := stdoutLimit.exceeded || stderrLimit.exceeded
+	if err != nil && !truncated {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("sandbox failed: %w: %s", err, msg)
 		}
-		s.logger.Printf("[%s] Input written to %s", runID, inputFile)
+		return nil, fmt.Errorf("sandbox failed: %w", err)
+	}
+	if res == nil {
+		// Stopped for its output before the sandbox could report anything.
+		res = &RunResult{ExitCode: -1}
 	}
 
-	execCtx, cancel := context.WithTimeout(ctx, s.executionTimeout)
-	defer cancel()
-
-	hostPath := strings.Replace(apiContainerTempDir, apiContainerBaseDir, s.hostTempDir, 1)
-	volumeMount := fmt.Sprintf("%s:/app", hostPath)
+	result := &ExecutionResult{
+		Output:    sanitizeOutput(stdout.String(), stdoutLimit.exceeded),
+		Error:     sanitizeOutput(stderr.String(), stderrLimit.exceeded),
+		ExitCode:  res.ExitCode,
+		TimedOut:  res.TimedOut && !truncated,
+		OOMKilled: res.OOMKilled,
+		Truncated: truncated,
+		Usage:     res.Usage,
+	}
+	if result.Usage == nil {
+		// Includes sandbox start-up, unlike what the sandbox measures itself.
+		result.Usage = &models.ResourceUsage{WallTimeMs: time.Since(runStart).Milliseconds()}
+	}
 
-	hostBuildCacheDir := strings.Replace(s.buildCacheDir, "/tmp/runbox", s.hostTempDir, 1)
-	hostModCacheDir := strings.Replace(s.modCacheDir, "/tmp/runbox", s.hostTempDir, 1)
+	switch {
+	case result.Truncated:
+		s.logger.Printf("[%s] Output limit of %d bytes exceeded.", ws.runID, s.maxOutputBytes)
+	case result.TimedOut:
+		s.logger.Printf("[%s] Time limit of %v exceeded.", ws.runID, req.Limits.TimeLimit)
+	case result.ExitCode != 0:
+		if result.Error == "" && !result.OOMKilled {
+			result.Error = fmt.Sprintf("exit status %d", result.ExitCode)
+		}
+		s.logger.Printf("[%s] Command failed with exit code %d (OOM killed: %v): %s", ws.runID, result.ExitCode, result.OOMKilled, result.Error)
+	default:
+		s.logger.Printf("[%s] Command executed successfully.", ws.runID)
+	}
 
-	cacheMount := fmt.Sprintf("%s:/root/.cache/go-build:rw", hostBuildCacheDir)
-	modMount := fmt.Sprintf("%s:/go/pkg/mod:rw", hostModCacheDir)
+	return result, nil
+}
 
-	s.logger.Printf("[%s] Container temp dir: %s", runID, apiContainerTempDir)
-	s.logger.Printf("[%s] Host mount path: %s", runID, hostPath)
+// compile builds the submission inside the workspace. It returns the build
+// output, with paths relative to the workspace, when the compiler rejected
+// the code and an empty string on success or for interpreted languages.
+func (s *service) compile(ctx context.Context, ws *workspace, lang *Language) (string, error) {
+	if lang.CompileCmd == "" {
+		return "", nil
+	}
 
-	runCmd := fmt.Sprintf("cd /app && GOFLAGS=-mod=readonly go run %s", codeFileName)
+	// Problem limits apply to the candidate's program, not to the compiler.
+	limits := lang.Limits
+	limits.TimeLimit = s.executionTimeout
 
-	if inputFile != "" {
-		runCmd = fmt.Sprintf("cd /app && cat input.txt | GOFLAGS=-mod=readonly go run %s", codeFileName)
+	s.logger.Printf("[%s] Compiling submission...", ws.runID)
+	result, err := s.runSandbox(ctx, ws, RunRequest{Lang: lang, Limits: limits, Script: lang.CompileCmd, Writable: true, WritableCaches: true}, nil)
+	if err != nil {
+		return "", fmt.Errorf("compilation failed: %w", err)
 	}
 
-	args := []string{
-		"run", "--rm",
-		"--network", "none",
-		"--memory", "256m",
-		"--cpus", "0.5",
-		"-v", volumeMount,
-		"-v", cacheMount,
-		"-v", modMount,
-		"-w", "/app",
-		"golang:1.22-alpine",
-		"sh", "-c", runCmd,
+	if result.TimedOut {
+		return fmt.Sprintf("compilation timed out after %v", s.executionTimeout), nil
+	}
+	if result.OOMKilled {
+		return fmt.Sprintf("compiler exceeded the memory limit (%d MB)", limits.MemoryMB), nil
 	}
 
-	cmd := exec.CommandContext(execCtx, "docker", args...)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	s.logger.Printf("[%s] Executing docker command: docker %v", runID, args)
-	dockerStart := time.Now()
-
-	err := cmd.Run()
+	if result.ExitCode != 0 {
+		compileError := strings.TrimSpace(result.Error)
+		if out := strings.TrimSpace(result.Output); out != "" {
+			compileError = strings.TrimSpace(out + "\n" + compileError)
+		}
+		return ws.relativize(compileError), nil
+	}
 
-	dockerDuration := time.Since(dockerStart)
-	s.logger.Printf("[%s] Docker command finished. (took %v)", runID, dockerDuration)
+	return "", nil
+}
 
-	if execCtx.Err() == context.DeadlineExceeded {
-		s.logger.Printf("[%s] CONTEXT DEADLINE EXCEEDED. Total execution time: %v", runID, dockerDuration)
-		return nil, fmt.Errorf("execution timed out after %v", s.executionTimeout)
+// run executes the already compiled submission once with the given stdin and
+// classifies how it ended.
+func (s *service) run(ctx context.Context, ws *workspace, lang *Language, limits Limits, name string, input string, out *outputStream) (*ExecutionResult, error) {
+	var inputFile string
+	if input != "" {
+		var err error
+		inputFile, err = ws.writeInput(name, input)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(inputFile)
+		s.logger.Printf("[%s] Input written to %s", ws.runID, inputFile)
 	}
 
-	result := &ExecutionResult{
-		Output: stdout.String(),
-		Error:  stderr.String(),
+	result, err := s.runSandbox(ctx, ws, RunRequest{Lang: lang, Limits: limits, Script: lang.RunCmd, StdinFile: inputFile}, out)
+	if err != nil {
+		return nil, err
 	}
 
-	if err != nil {
-		if result.Error == "" {
-			result.Error = err.Error()
+	result.Verdict = runVerdict(result)
+	result.Error = ws.relativize(result.Error)
+	if result.Error == "" {
+		result.Error = verdictMessage(result.Verdict, limits)
+	}
+	if result.Verdict == models.VerdictRuntimeError && lang.Name == "go" {
+		if d := parseGoPanic(result.Error); d != nil {
+			result.Diagnostics = []models.Diagnostic{*d}
 		}
-		s.logger.Printf("[%s] Command failed with error: %s", runID, result.Error)
-	} else {
-		s.logger.Printf("[%s] Command executed successfully.", runID)
 	}
 
 	return result, nil
 }
 
-func (s *service) Execute(ctx context.Context, code string, language string) (*ExecutionResult, error) {
+func (s *service) Execute(ctx context.Context, code string, language string, opts ...Option) (result *ExecutionResult, err error) {
+	o := newExecOptions(opts)
+
 	overallStart := time.Now()
 	s.logger.Printf("-------------------------------------------------")
 	s.logger.Println("Received new execution request.")
 
-	s.ensureDockerImageAvailable("golang:1.22-alpine")
+	defer func() {
+		s.logger.Printf("Total request processing time: %v", time.Since(overallStart))
+		s.logger.Printf("-------------------------------------------------")
+	}()
 
-	result, err := s.executeCode(ctx, code, language, "")
+	execution := newExecution(codeHash(code, o.files), language, o)
+	defer func() { s.recordRun(ctx, execution, result, err) }()
 
-	s.logger.Printf("Total request processing time: %v", time.Since(overallStart))
-	s.logger.Printf("-------------------------------------------------")
+	lang, err := s.resolveExecution(ctx, execution, language, o)
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string]string
+	code, lang, files, err = s.prepareFiles(code, lang, o.files)
+	if errors.Is(err, ErrDisallowedImport) {
+		return &ExecutionResult{CompileError: err.Error(), Verdict: models.VerdictCompilationError}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sandbox.Prepare(ctx, lang); err != nil {
+		return nil, err
+	}
+
+	ws, err := s.newWorkspace(code, lang, files)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.remove()
+
+	compileError, err := s.compile(ctx, ws, lang)
+	if err != nil {
+		return nil, err
+	}
+	if compileError != "" {
+		return &ExecutionResult{
+			CompileError: compileError,
+			Verdict:      models.VerdictCompilationError,
+			Diagnostics:  parseCompileDiagnostics(compileError),
+		}, nil
+	}
 
-	return result, err
+	return s.run(ctx, ws, lang, s.runLimits(lang, o), "run", "", o.outputStream(0, 0))
 }
 
-func (s *service) ExecuteWithTestCases(ctx context.Context, code string, language string, testCases []*models.TestCase) (*models.ExecutionResults, error) {
+func (s *service) ExecuteWithTestCases(ctx context.Context, code string, language string, testCases []*models.TestCase, opts ...Option) (results *models.ExecutionResults, err error) {
+	o := newExecOptions(opts)
+
 	overallStart := time.Now()
 	s.logger.Printf("-------------------------------------------------")
 	s.logger.Println("Received execution request with test cases.")
 
-	s.ensureDockerImageAvailable("golang:1.22-alpine")
+	defer func() {
+		s.logger.Printf("Total request processing time: %v", time.Since(overallStart))
+		s.logger.Printf("-------------------------------------------------")
+	}()
 
-	var testResults []models.TestResult
-	success := true
+	execution := o.execution
+	if execution == nil {
+		execution = newExecution(codeHash(code, o.files), language, o)
+		defer func() { s.record(ctx, execution, results, err) }()
+	}
+
+	lang, err := s.resolveExecution(ctx, execution, language, o)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, testCase := range testCases {
-		s.logger.Printf("Running test case %d", testCase.ID)
+	var files map[string]string
+	code, lang, files, err = s.prepareFiles(code, lang, o.files)
+	if errors.Is(err, ErrDisallowedImport) {
+		return &models.ExecutionResults{
+			Success:      false,
+			Verdict:      models.VerdictCompilationError,
+			CompileError: err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		result, err := s.executeCode(ctx, code, language, testCase.Input)
-		if err != nil {
+	if o.signature != nil {
+		if err := checkHarness(lang, o.signature); err != nil {
 			return nil, err
 		}
-
-		actualOutput := strings.TrimSpace(result.Output)
-		expectedOutput := strings.TrimSpace(testCase.ExpectedOutput)
-
-		passed := actualOutput == expectedOutput
-		if !passed {
-			success = false
+		wrapped, err := lang.Harness(o.signature, code)
+		if err != nil {
+			return &models.ExecutionResults{
+				Success:      false,
+				Verdict:      models.VerdictCompilationError,
+				CompileError: err.Error(),
+			}, nil
 		}
+		code = wrapped
+	}
 
-		testResult := models.TestResult{
-			TestCaseID:     testCase.ID,
-			Input:          testCase.Input,
-			ExpectedOutput: testCase.ExpectedOutput,
-			ActualOutput:   actualOutput,
-			Error:          result.Error,
-			Passed:         passed,
+	limits := s.runLimits(lang, o)
+	var cacheKey string
+	if s.resultCache != nil {
+		cacheKey = resultKey(code, lang, files, limits, s.maxOutputBytes, o.checker, testCases)
+		if cached, ok := s.resultCache.Get(ctx, cacheKey); ok {
+			s.logger.Printf("Serving cached results %s", cacheKey)
+			// Replay the events of a run so that clients see every test
+			// case start before it finishes.
+			for i := range cached.TestResults {
+				o.emit(Event{Type: EventTestCaseStarted, Index: i, TestCaseID: cached.TestResults[i].TestCaseID})
+				o.emit(Event{Type: EventTestCaseFinished, Index: i, TestCaseID: cached.TestResults[i].TestCaseID, Result: &cached.TestResults[i]})
+			}
+			cached.Cached = true
+			return cached, nil
 		}
+	}
 
-		if testCase.IsHidden {
-			testResult.Input = ""
-			testResult.ExpectedOutput = ""
-		}
+	results, err = s.executeWithTestCases(ctx, code, lang, files, limits, testCases, o)
+	if err != nil {
+		return nil, err
+	}
+	if s.resultCache != nil && cacheable(results) {
+		s.resultCache.Put(ctx, cacheKey, results)
+	}
+	return results, nil
+}
 
-		testResults = append(testResults, testResult)
+// executeWithTestCases builds the prepared submission and runs it against
+// the test cases.
+func (s *service) executeWithTestCases(ctx context.Context, code string, lang *Language, files map[string]string, limits Limits, testCases []*models.TestCase, o *execOptions) (*models.ExecutionResults, error) {
+	if err := s.sandbox.Prepare(ctx, lang); err != nil {
+		return nil, err
 	}
 
-	s.logger.Printf("Total request processing time: %v", time.Since(overallStart))
-	s.logger.Printf("-------------------------------------------------")
+	ws, err := s.newWorkspace(code, lang, files)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.remove()
+
+	compileError, err := s.compile(ctx, ws, lang)
+	if err != nil {
+		return nil, err
+	}
+	if compileError != "" {
+		s.logger.Printf("[%s] Compilation failed, skipping %d test cases", ws.runID, len(testCases))
+		return &models.ExecutionResults{
+			Success:      false,
+			Verdict:      models.VerdictCompilationError,
+			CompileError: compileError,
+			Diagnostics:  parseCompileDiagnostics(compileError),
+		}, nil
+	}
+
+	judge, err := s.newJudge(ctx, o.checker)
+	if err != nil {
+		return nil, err
+	}
+	defer judge.close()
+
+	testResults, err := s.runTestCases(ctx, ws, lang, limits, judge, testCases, o)
+	if err != nil {
+		return nil, err
+	}
+
+	success := true
+	for _, testResult := range testResults {
+		if !testResult.Passed {
+			success = false
+		}
+	}
 
 	return &models.ExecutionResults{
 		Success:     success,
+		Verdict:     overallVerdict(testResults),
 		TestResults: testResults,
 	}, nil
 }
 
-func (s *service) ExecuteForProblem(ctx context.Context, code string, language string, problemID int) (*models.ExecutionResults, error) {
+// runLimits resolves the limits of the candidate's program: the language
+// defaults, overridden field by field by WithLimits. The time limit, default
+// or overridden, is scaled by the language's TimeMultiplier.
+func (s *service) runLimits(lang *Language, o *execOptions) Limits {
+	limits := lang.Limits
+	if limits.TimeLimit <= 0 {
+		limits.TimeLimit = s.executionTimeout
+	}
+
+	if o.limits != nil {
+		if o.limits.TimeLimit > 0 {
+			limits.TimeLimit = o.limits.TimeLimit
+		}
+		if o.limits.MemoryMB > 0 {
+			limits.MemoryMB = o.limits.MemoryMB
+		}
+		if o.limits.CPUs > 0 {
+			limits.CPUs = o.limits.CPUs
+		}
+	}
+
+	if lang.TimeMultiplier > 0 {
+		limits.TimeLimit = time.Duration(float64(limits.TimeLimit) * lang.TimeMultiplier)
+	}
+	return limits
+}
+
+// runTestCases runs up to maxParallelTestCases test cases at a time and
+// returns their results in the same order as testCases.
+func (s *service) runTestCases(ctx context.Context, ws *workspace, lang *Language, limits Limits, judge *judge, testCases []*models.TestCase, o *execOptions) ([]models.TestResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	testResults := make([]models.TestResult, len(testCases))
+	fanOut := make(chan struct{}, s.maxParallelTestCases)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	for i, testCase := range testCases {
+		select {
+		case fanOut <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, testCase *models.TestCase) {
+			defer wg.Done()
+			defer func() { <-fanOut }()
+
+			testResult, err := s.runTestCase(ctx, ws, lang, limits, judge, i, testCase, o)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			testResults[i] = *testResult
+		}(i, testCase)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return testResults, nil
+}
+
+func (s *service) runTestCase(ctx context.Context, ws *workspace, lang *Language, limits Limits, judge *judge, index int, testCase *models.TestCase, o *execOptions) (*models.TestResult, error) {
+	s.logger.Printf("Running test case %d", testCase.ID)
+	o.emit(Event{Type: EventTestCaseStarted, Index: index, TestCaseID: testCase.ID})
+
+	testResult := &models.TestResult{
+		TestCaseID:     testCase.ID,
+		Input:          testCase.Input,
+		ExpectedOutput: testCase.ExpectedOutput,
+	}
+
+	name := "case-" + strconv.Itoa(index)
+	result, err := s.run(ctx, ws, lang, limits, name, testCase.Input, o.outputStream(index, testCase.ID))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		// Infrastructure failures only fail this test case.
+		s.logger.Printf("[%s] Test case %d failed to run: %v", ws.runID, testCase.ID, err)
+		testResult.Verdict = models.VerdictInternalError
+		testResult.Error = err.Error()
+	} else {
+		testResult.ActualOutput = strings.TrimSpace(result.Output)
+		testResult.Error = result.Error
+		testResult.Truncated = result.Truncated
+		testResult.Verdict = result.Verdict
+		testResult.Diagnostics = result.Diagnostics
+		testResult.Usage = result.Usage
+		if testResult.Verdict == models.VerdictAccepted {
+			verdict, message, err := judge.check(ctx, name, testCase, result.Output)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, err
+				}
+				s.logger.Printf("[%s] Checker failed on test case %d: %v", ws.runID, testCase.ID, err)
+				verdict = models.VerdictInternalError
+				testResult.Error = err.Error()
+			}
+			testResult.Verdict = verdict
+			testResult.CheckerMessage = message
+		}
+	}
+	testResult.Passed = testResult.Verdict == models.VerdictAccepted
+
+	if testCase.IsHidden {
+		testResult.Input = ""
+		testResult.ExpectedOutput = ""
+	}
+
+	o.emit(Event{Type: EventTestCaseFinished, Index: index, TestCaseID: testCase.ID, Result: testResult})
+
+	return testResult, nil
+}
+
+func (s *service) ExecuteForProblem(ctx context.Context, code string, language string, problemID int, opts ...Option) (results *models.ExecutionResults, err error) {
 	s.logger.Printf("Executing code for problem %d", problemID)
+	opts = append(opts, withProblem(problemID))
+
+	// Recorded here, so that failing to load the problem is recorded too.
+	o := newExecOptions(opts)
+	execution := newExecution(codeHash(code, o.files), language, o)
+	defer func() { s.record(ctx, execution, results, err) }()
+	opts = append(opts, withExecution(execution))
+
+	problem, err := s.problems.GetProblemByID(ctx, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem %d: %w", problemID, err)
+	}
+
+	if problem.GoVersion != nil {
+		if lang, err := s.languages.Get(language); err == nil && lang.Name == "go" {
+			if requested := o.version; requested != "" && requested != *problem.GoVersion {
+				return nil, fmt.Errorf("%w: problem %d requires Go %s", ErrUnsupportedVersion, problemID, *problem.GoVersion)
+			}
+			opts = append(opts, WithVersion(*problem.GoVersion))
+		}
+	}
+
+	if problem.TestFile != nil {
+		opts = append([]Option{WithFiles(problem.Files), WithLimits(problemLimits(problem))}, opts...)
+		return s.executeTestSuite(ctx, code, language, *problem.TestFile, opts...)
+	}
 
 	testCases, err := s.repository.GetTestCasesByProblemID(ctx, problemID)
 	if err != nil {
@@ -265,5 +645,34 @@ func (s *service) ExecuteForProblem(ctx context.Context, code string, language s
 		return nil, fmt.Errorf("no test cases found for problem %d", problemID)
 	}
 
-	return s.ExecuteWithTestCases(ctx, code, language, testCases)
+	checker := problem.Checker
+	if checker == nil && problem.Signature != nil {
+		checker = &models.Checker{Type: models.CheckerJSON}
+	}
+
+	// Problem settings go first so explicit options from the caller win;
+	// submitted files replace scaffolding files with the same path.
+	opts = append([]Option{
+		WithFiles(problem.Files),
+		WithLimits(problemLimits(problem)),
+		WithChecker(checker),
+		WithFunctionSignature(problem.Signature),
+	}, opts...)
+	return s.ExecuteWithTestCases(ctx, code, language, testCases, opts...)
+}
+
+// problemLimits converts the optional limits stored on a problem; unset
+// fields stay zero and keep the language defaults.
+func problemLimits(problem *models.Problem) Limits {
+	var limits Limits
+	if problem.TimeLimitMs != nil {
+		limits.TimeLimit = time.Duration(*problem.TimeLimitMs) * time.Millisecond
+	}
+	if problem.MemoryLimitMB != nil {
+		limits.MemoryMB = *problem.MemoryLimitMB
+	}
+	if problem.CPUQuota != nil {
+		limits.CPUs = *problem.CPUQuota
+	}
+	return limits
 }
@@ -0,0 +1,129 @@
+package code_executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidFiles is returned when a submitted file tree has an unsafe path
+// or exceeds the size limits.
+var ErrInvalidFiles = errors.New("invalid submission files")
+
+const (
+	// defaultMaxSubmissionBytes bounds the total size of a submission when
+	// Config.MaxSubmissionBytes is not set.
+	defaultMaxSubmissionBytes = 1 << 20
+	maxSubmissionFiles        = 100
+	maxFilePathLength         = 255
+)
+
+// validateFiles checks that every path stays inside the workspace and that
+// the tree, together with the main source, fits the size limit.
+func validateFiles(code string, files map[string]string, maxBytes int) error {
+	if len(files) > maxSubmissionFiles {
+		return fmt.Errorf("%w: %d files, at most %d allowed", ErrInvalidFiles, len(files), maxSubmissionFiles)
+	}
+
+	total := len(code)
+	for name, content := range files {
+		if err := validateFilePath(name); err != nil {
+			return err
+		}
+		total += len(content)
+	}
+
+	if total > maxBytes {
+		return fmt.Errorf("%w: %d bytes, at most %d allowed", ErrInvalidFiles, total, maxBytes)
+	}
+	return nil
+}
+
+func validateFilePath(name string) error {
+	switch {
+	case name == "" || len(name) > maxFilePathLength:
+		return fmt.Errorf("%w: invalid path %q", ErrInvalidFiles, name)
+	case strings.Contains(name, "\\") || strings.ContainsRune(name, 0):
+		return fmt.Errorf("%w: invalid characters in path %q", ErrInvalidFiles, name)
+	case path.IsAbs(name):
+		return fmt.Errorf("%w: absolute path %q", ErrInvalidFiles, name)
+	case path.Clean(name) != name || name == "." || name == ".." || strings.HasPrefix(name, "../"):
+		return fmt.Errorf("%w: path %q must be relative and clean", ErrInvalidFiles, name)
+	}
+	return nil
+}
+
+// writeFiles materializes a validated file tree under the workspace.
+func (ws *workspace) writeFiles(files map[string]string) error {
+	for name, content := range files {
+		target := filepath.Join(ws.dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// prepareFiles validates a multi-file submission and returns the main source,
+// taken from files when code is empty, and checks the submission's imports
+// against the allowed modules. For languages that build a whole
+// project it returns a copy of lang using ProjectCompileCmd and adds the
+// default manifest, e.g. go.mod, when the submission has none.
+func (s *service) prepareFiles(code string, lang *Language, files map[string]string) (string, *Language, map[string]string, error) {
+	if len(files) > 0 {
+		if err := validateFiles(code, files, s.maxSubmissionBytes); err != nil {
+			return "", nil, nil, err
+		}
+		if code == "" {
+			code = files[lang.SourceFile]
+		}
+		if code == "" {
+			return "", nil, nil, fmt.Errorf("%w: missing %s", ErrInvalidFiles, lang.SourceFile)
+		}
+	}
+
+	if lang.Dependencies != nil {
+		extra, err := lang.Dependencies(code, files, s.allowedModules)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if len(extra) > 0 {
+			merged := make(map[string]string, len(files)+len(extra))
+			for name, content := range files {
+				merged[name] = content
+			}
+			for name, content := range extra {
+				merged[name] = content
+			}
+			files = merged
+		}
+	}
+
+	if len(files) == 0 {
+		return code, lang, nil, nil
+	}
+
+	if lang.ProjectCompileCmd == "" {
+		return code, lang, files, nil
+	}
+
+	project := *lang
+	project.CompileCmd = lang.ProjectCompileCmd
+	if project.Manifest != "" {
+		if _, ok := files[project.Manifest]; !ok {
+			withManifest := make(map[string]string, len(files)+1)
+			for name, content := range files {
+				withManifest[name] = content
+			}
+			withManifest[project.Manifest] = project.DefaultManifest
+			files = withManifest
+		}
+	}
+	return code, &project, files, nil
+}
@@ -0,0 +1,75 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-code-runner/internal/models"
+)
+
+// ErrFormatUnsupported is returned when formatting is requested for a
+// language without a formatter.
+var ErrFormatUnsupported = errors.New("formatting unsupported")
+
+// FormatResult is the outcome of Service.Format: the formatted source, or
+// the syntax errors that kept the formatter from producing it.
+type FormatResult struct {
+	Code        string
+	Diagnostics []models.Diagnostic
+}
+
+// Format runs the language's canonical formatter, e.g. gofmt, over code in
+// the sandbox. Source the formatter cannot parse is reported as error
+// diagnostics with an empty Code.
+func (s *service) Format(ctx context.Context, code string, language string, opts ...Option) (*FormatResult, error) {
+	o := newExecOptions(opts)
+
+	lang, err := s.resolveLanguage(language, o)
+	if err != nil {
+		return nil, err
+	}
+	if lang.FormatCmd == "" {
+		return nil, fmt.Errorf("%w for %s", ErrFormatUnsupported, lang.Name)
+	}
+	if err := validateFiles(code, nil, s.maxSubmissionBytes); err != nil {
+		return nil, err
+	}
+
+	if err := s.sandbox.Prepare(ctx, lang); err != nil {
+		return nil, err
+	}
+
+	ws, err := s.newWorkspace(code, lang, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.remove()
+
+	limits := lang.Limits
+	limits.TimeLimit = s.executionTimeout
+
+	s.logger.Printf("[%s] Formatting submission...", ws.runID)
+	result, err := s.runSandbox(ctx, ws, RunRequest{Lang: lang, Limits: limits, Script: lang.FormatCmd}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("formatting failed: %w", err)
+	}
+
+	var message string
+	switch {
+	case result.TimedOut || result.OOMKilled:
+		message = toolFailure(result, limits)
+	case result.Truncated:
+		message = fmt.Sprintf("formatted source exceeds the output limit (%d bytes)", s.maxOutputBytes)
+	case result.ExitCode != 0:
+		output := ws.relativize(result.Error)
+		if diagnostics := parseCompileDiagnostics(output); len(diagnostics) > 0 {
+			return &FormatResult{Diagnostics: diagnostics}, nil
+		}
+		message = strings.TrimSpace(output)
+	default:
+		return &FormatResult{Code: result.Output, Diagnostics: []models.Diagnostic{}}, nil
+	}
+	return &FormatResult{Diagnostics: []models.Diagnostic{{Severity: "error", Message: message}}}, nil
+}
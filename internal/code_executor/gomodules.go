@@ -0,0 +1,399 @@
+package code_executor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-code-runner/internal/platform/docker"
+)
+
+// ErrDisallowedImport is returned when a submission imports a third-party
+// package that is not provided by an allowed module. It is reported to the
+// candidate as a compilation error.
+var ErrDisallowedImport = errors.New("import not allowed")
+
+// Module is a third-party module version submissions may depend on.
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+func (m Module) String() string {
+	return m.Path + "@" + m.Version
+}
+
+// ParseModules parses "path@version" specs as used in the configuration.
+func ParseModules(specs []string) ([]Module, error) {
+	modules := make([]Module, 0, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		modulePath, version, ok := strings.Cut(spec, "@")
+		if !ok || modulePath == "" || !strings.HasPrefix(version, "v") {
+			return nil, fmt.Errorf("invalid module %q, expected path@version", spec)
+		}
+		modules = append(modules, Module{Path: modulePath, Version: version})
+	}
+	return modules, nil
+}
+
+// DependencyFunc checks the third-party imports of a submission against the
+// allowed modules before anything runs. It returns extra files to write,
+// e.g. a go.mod requiring the imported modules.
+type DependencyFunc func(code string, files map[string]string, allowed []Module) (map[string]string, error)
+
+// goDependencies allows standard library imports, packages of the
+// submission's own module and packages of allowed modules. A go.mod is
+// generated when the submission has none but imports allowed modules.
+func goDependencies(code string, files map[string]string, allowed []Module) (map[string]string, error) {
+	sources := map[string]string{"main.go": code}
+	for name, content := range files {
+		if strings.HasSuffix(name, ".go") && name != "main.go" {
+			sources[name] = content
+		}
+	}
+
+	goMod, hasGoMod := files["go.mod"]
+	ownModule := ""
+	if hasGoMod {
+		ownModule = goModulePath(goMod)
+		for _, required := range goModRequires(goMod) {
+			if !containsModule(allowed, required) {
+				return nil, fmt.Errorf("%w: go.mod requires %s, allowed modules: %s", ErrDisallowedImport, required, moduleList(allowed))
+			}
+		}
+	}
+
+	used := make(map[Module]bool)
+	for name, src := range sources {
+		file, err := parser.ParseFile(token.NewFileSet(), name, src, parser.ImportsOnly)
+		if err != nil {
+			// Syntax errors are left to the compiler.
+			continue
+		}
+		for _, spec := range file.Imports {
+			importPath := strings.Trim(spec.Path.Value, `"`)
+			if isStdImport(importPath) || (ownModule != "" && inModule(importPath, ownModule)) {
+				continue
+			}
+			module, ok := moduleFor(allowed, importPath)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s imports %q, allowed modules: %s", ErrDisallowedImport, name, importPath, moduleList(allowed))
+			}
+			used[module] = true
+		}
+	}
+
+	if hasGoMod || len(used) == 0 {
+		return nil, nil
+	}
+
+	requires := make([]string, 0, len(used))
+	for module := range used {
+		requires = append(requires, "\t"+module.Path+" "+module.Version)
+	}
+	sort.Strings(requires)
+
+	return map[string]string{
+		"go.mod": "module submission\n\ngo 1.21\n\nrequire (\n" + strings.Join(requires, "\n") + "\n)\n",
+	}, nil
+}
+
+// isStdImport reports whether importPath belongs to the standard library,
+// whose first path element never contains a dot.
+func isStdImport(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+func inModule(importPath, modulePath string) bool {
+	return importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")
+}
+
+func moduleFor(allowed []Module, importPath string) (Module, bool) {
+	var best Module
+	for _, module := range allowed {
+		if inModule(importPath, module.Path) && len(module.Path) > len(best.Path) {
+			best = module
+		}
+	}
+	return best, best.Path != ""
+}
+
+func containsModule(allowed []Module, module Module) bool {
+	for _, m := range allowed {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+func moduleList(allowed []Module) string {
+	if len(allowed) == 0 {
+		return "none"
+	}
+	names := make([]string, len(allowed))
+	for i, module := range allowed {
+		names[i] = module.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+func goModulePath(goMod string) string {
+	for _, line := range strings.Split(goMod, "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// goModRequires returns the modules listed in require directives, both the
+// single-line and the block form.
+func goModRequires(goMod string) []Module {
+	var (
+		requires []Module
+		inBlock  bool
+	)
+	for _, line := range strings.Split(goMod, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock && len(fields) >= 2:
+			requires = append(requires, Module{Path: fields[0], Version: fields[1]})
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "require" && len(fields) >= 3:
+			requires = append(requires, Module{Path: fields[1], Version: fields[2]})
+		}
+	}
+	return requires
+}
+
+// SeedGoModules downloads the allowed modules into the language's shared
+// module cache from source, a directory or .zip/.tar.gz archive in GOPROXY
+// layout. The download runs in the language's image without network access.
+func SeedGoModules(ctx context.Context, client *docker.Client, lang *Language, source string, modules []Module, logger *log.Logger) error {
+	if len(modules) == 0 {
+		return nil
+	}
+
+	proxyDir := filepath.Join(apiContainerBaseDir, "goproxy")
+	if err := os.RemoveAll(proxyDir); err != nil {
+		return fmt.Errorf("failed to clean %s: %w", proxyDir, err)
+	}
+	if err := copyModuleSource(source, proxyDir); err != nil {
+		return fmt.Errorf("failed to prepare module source %s: %w", source, err)
+	}
+	defer os.RemoveAll(proxyDir)
+
+	hostDir := hostTempDir()
+	cfg := &docker.ContainerConfig{
+		Image: lang.Image,
+		Cmd:   []string{"go", "mod", "download"},
+		Env: []string{
+			"GOPROXY=file:///goproxy",
+			"GOSUMDB=off",
+			"GOFLAGS=-mod=mod",
+			"GOTOOLCHAIN=local",
+		},
+		HostConfig: docker.HostConfig{
+			Binds:       []string{filepath.Join(hostDir, "goproxy") + ":/goproxy:ro"},
+			NetworkMode: "none",
+		},
+	}
+	for _, cache := range lang.Caches {
+		cacheDir := filepath.Join(apiContainerBaseDir, cache.Name)
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", cacheDir, err)
+		}
+		cfg.HostConfig.Binds = append(cfg.HostConfig.Binds, filepath.Join(hostDir, cache.Name)+":"+cache.Target)
+	}
+	for _, module := range modules {
+		cfg.Cmd = append(cfg.Cmd, module.String())
+	}
+
+	logger.Printf("Seeding Go module cache with %s", moduleList(modules))
+
+	exists, err := client.ImageExists(ctx, lang.Image)
+	if err == nil && !exists {
+		err = client.PullImage(ctx, lang.Image)
+	}
+	if err != nil {
+		return err
+	}
+
+	id, err := client.CreateContainer(ctx, "", cfg)
+	if err != nil {
+		return err
+	}
+	defer client.RemoveContainer(context.Background(), id)
+
+	if err := client.StartContainer(ctx, id); err != nil {
+		return err
+	}
+	exitCode, err := client.WaitContainer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		var out strings.Builder
+		client.FollowLogs(ctx, id, &out, &out)
+		return fmt.Errorf("go mod download failed with exit code %d: %s", exitCode, strings.TrimSpace(out.String()))
+	}
+	logger.Printf("Go module cache seeded")
+	return nil
+}
+
+// copyModuleSource copies a GOPROXY layout directory or extracts an archive
+// of one into dst.
+func copyModuleSource(source, dst string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.IsDir():
+		return copyDir(source, dst)
+	case strings.HasSuffix(source, ".zip"):
+		return extractZip(source, dst)
+	case strings.HasSuffix(source, ".tar.gz"), strings.HasSuffix(source, ".tgz"):
+		return extractTarGz(source, dst)
+	default:
+		return fmt.Errorf("unsupported module source, expected a directory, .zip or .tar.gz")
+	}
+}
+
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		return writeExtracted(target, in)
+	})
+}
+
+func extractZip(archive, dst string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		target, err := archiveTarget(dst, f.Name)
+		if err != nil {
+			return err
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeExtracted(target, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTarGz(archive, dst string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		target, err := archiveTarget(dst, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if err := writeExtracted(target, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// archiveTarget resolves an archive entry below dst, rejecting entries that
+// would escape it.
+func archiveTarget(dst, name string) (string, error) {
+	clean := path.Clean("/" + name)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid archive entry %q", name)
+	}
+	return filepath.Join(dst, filepath.FromSlash(clean)), nil
+}
+
+func writeExtracted(target string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,126 @@
+package code_executor
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"go-code-runner/internal/models"
+)
+
+// HarnessFunc turns a candidate's function into a complete program that reads
+// one JSON argument per stdin line, calls the function and prints the JSON
+// encoded result. An error means the candidate's code cannot be wrapped and
+// is reported as a compilation error.
+type HarnessFunc func(sig *models.FunctionSignature, code string) (string, error)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// signatureBaseTypes maps the portable signature types to Go types.
+var signatureBaseTypes = map[string]string{
+	"int":    "int",
+	"float":  "float64",
+	"string": "string",
+	"bool":   "bool",
+}
+
+func validateSignature(sig *models.FunctionSignature) error {
+	if !identifierPattern.MatchString(sig.Name) {
+		return fmt.Errorf("invalid function name %q", sig.Name)
+	}
+	for _, param := range sig.Params {
+		if !identifierPattern.MatchString(param.Name) {
+			return fmt.Errorf("invalid parameter name %q", param.Name)
+		}
+		if _, err := goType(param.Type); err != nil {
+			return err
+		}
+	}
+	if _, err := goType(sig.ReturnType); err != nil {
+		return err
+	}
+	return nil
+}
+
+// goType converts a signature type such as "int[][]" to "[][]int".
+func goType(t string) (string, error) {
+	base := strings.TrimRight(t, "[]")
+	dims := strings.Count(t[len(base):], "[]")
+	goBase, ok := signatureBaseTypes[base]
+	if !ok || len(t) != len(base)+2*dims {
+		return "", fmt.Errorf("invalid type %q", t)
+	}
+	return strings.Repeat("[]", dims) + goBase, nil
+}
+
+// goHarness adds the imports it needs on the package clause line, so the
+// compiler's line numbers still match the candidate's code.
+func goHarness(sig *models.FunctionSignature, code string) (string, error) {
+	imports := `import __json "encoding/json"; import __os "os"`
+
+	var src string
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", code, parser.PackageClauseOnly)
+	if err == nil {
+		if file.Name.Name != "main" {
+			return "", fmt.Errorf("function problems must use package main, got package %s", file.Name.Name)
+		}
+		end := int(file.Name.End()) - 1
+		src = code[:end] + "; " + imports + code[end:]
+	} else {
+		src = "package main; " + imports + "; " + code
+	}
+
+	var b strings.Builder
+	b.WriteString(src)
+	b.WriteString("\n\nfunc main() {\n\t__dec := __json.NewDecoder(__os.Stdin)\n")
+
+	args := make([]string, len(sig.Params))
+	for i, param := range sig.Params {
+		typ, _ := goType(param.Type)
+		args[i] = fmt.Sprintf("__arg%d", i)
+		fmt.Fprintf(&b, "\tvar %s %s\n", args[i], typ)
+		fmt.Fprintf(&b, "\tif err := __dec.Decode(&%s); err != nil {\n", args[i])
+		fmt.Fprintf(&b, "\t\t__os.Stderr.WriteString(%q + err.Error() + \"\\n\")\n", "failed to decode argument "+param.Name+": ")
+		b.WriteString("\t\t__os.Exit(1)\n\t}\n")
+	}
+
+	fmt.Fprintf(&b, "\t__out, err := __json.Marshal(%s(%s))\n", sig.Name, strings.Join(args, ", "))
+	b.WriteString("\tif err != nil {\n")
+	b.WriteString("\t\t__os.Stderr.WriteString(\"failed to encode result: \" + err.Error() + \"\\n\")\n")
+	b.WriteString("\t\t__os.Exit(1)\n\t}\n")
+	b.WriteString("\t__os.Stdout.Write(append(__out, '\\n'))\n}\n")
+
+	return b.String(), nil
+}
+
+func pythonHarness(sig *models.FunctionSignature, code string) (string, error) {
+	return code + fmt.Sprintf(`
+
+if __name__ == "__main__":
+    import json as __json, sys as __sys
+    __args = [__json.loads(__line) for __line in __sys.stdin.read().splitlines() if __line.strip()]
+    print(__json.dumps(%s(*__args), separators=(",", ":")))
+`, sig.Name), nil
+}
+
+func javascriptHarness(sig *models.FunctionSignature, code string) (string, error) {
+	return code + fmt.Sprintf(`
+;(() => {
+  const __args = require("fs").readFileSync(0, "utf8").split("\n").filter((line) => line.trim() !== "").map((line) => JSON.parse(line));
+  console.log(JSON.stringify(%s(...__args)));
+})();
+`, sig.Name), nil
+}
+
+// checkHarness reports whether lang can run a function problem with sig.
+func checkHarness(lang *Language, sig *models.FunctionSignature) error {
+	if lang.Harness == nil {
+		return fmt.Errorf("%w %q for function problems", ErrUnsupportedLanguage, lang.Name)
+	}
+	if err := validateSignature(sig); err != nil {
+		return fmt.Errorf("invalid function signature: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package code_executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-code-runner/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ExecutionHistory records every execution for auditing, see
+// models.Execution. Implementations must be safe for concurrent use.
+type ExecutionHistory interface {
+	CreateExecution(ctx context.Context, execution *models.Execution) error
+}
+
+// CodingTests looks up the coding tests executions are attributed to. A
+// missing test is reported as pgx.ErrNoRows.
+type CodingTests interface {
+	GetTestByID(ctx context.Context, id string) (*models.CodingTest, error)
+}
+
+// ErrInvalidCodingTest is returned when WithCodingTest names a coding test
+// that does not exist or that the execution may not be attributed to.
+var ErrInvalidCodingTest = errors.New("invalid coding test")
+
+const (
+	// maxCodingTestIDLength is the length of coding test IDs, which are
+	// UUIDs.
+	maxCodingTestIDLength = 36
+	// maxLanguageLength is the size of the language column of the history.
+	maxLanguageLength = 50
+)
+
+// WithCodingTest attributes the execution to a candidate's coding test in
+// the execution history. With WithTenant the test must belong to the tenant,
+// otherwise it must be in progress and the execution runs on behalf of the
+// test's company, see Config.CodingTests.
+func WithCodingTest(testID string) Option {
+	return func(o *execOptions) {
+		o.testID = testID
+	}
+}
+
+// withProblem attributes the execution to the problem it is graded for.
+func withProblem(problemID int) Option {
+	return func(o *execOptions) {
+		o.problemID = problemID
+	}
+}
+
+// withExecution completes the audit record of an execution started by the
+// caller, which also stores it, see ExecuteForProblem.
+func withExecution(execution *models.Execution) Option {
+	return func(o *execOptions) {
+		o.execution = execution
+	}
+}
+
+// codeHash identifies a submission by its code and files without storing
+// them.
+func codeHash(code string, files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	h.Write([]byte(code))
+	for _, name := range names {
+		// NUL cannot occur in paths, so entries cannot run into each other.
+		h.Write([]byte("\x00" + name + "\x00" + files[name]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newExecution starts the audit record of an execution of the requested
+// language, which is recorded as given until resolveExecution resolves it, so
+// executions of unknown languages are recorded too. hash must be taken of
+// the code and files as submitted.
+func newExecution(hash string, language string, o *execOptions) *models.Execution {
+	if len(language) > maxLanguageLength {
+		// Cut on a rune boundary, the column only takes valid UTF-8.
+		language = strings.ToValidUTF8(language[:maxLanguageLength], "")
+	}
+	execution := &models.Execution{
+		CodeHash:  hash,
+		Language:  language,
+		StartedAt: time.Now(),
+	}
+	if o.problemID > 0 {
+		execution.ProblemID = &o.problemID
+	}
+	if o.tenant > 0 {
+		execution.CompanyID = &o.tenant
+	}
+	return execution
+}
+
+// resolveExecution attributes an execution started with newExecution to the
+// coding test of WithCodingTest once the test checks out, and resolves its
+// language for the tenant, which a candidate's test may have set.
+func (s *service) resolveExecution(ctx context.Context, execution *models.Execution, language string, o *execOptions) (*Language, error) {
+	if o.testID != "" {
+		if len(o.testID) > maxCodingTestIDLength {
+			return nil, fmt.Errorf("%w: test ID longer than %d characters", ErrInvalidCodingTest, maxCodingTestIDLength)
+		}
+		if s.codingTests != nil {
+			if err := s.checkCodingTest(ctx, o); err != nil {
+				return nil, err
+			}
+			execution.TestID = &o.testID
+			if o.tenant > 0 {
+				execution.CompanyID = &o.tenant
+			}
+		}
+	}
+
+	lang, err := s.resolveLanguage(language, o)
+	if err != nil {
+		return nil, err
+	}
+	execution.Language = lang.Name
+	execution.Version = lang.DefaultVersion
+	return lang, nil
+}
+
+// checkCodingTest verifies that the execution may be attributed to the
+// coding test of WithCodingTest: a company may attribute runs to its own
+// tests, a candidate without an API key only to a test in progress, which
+// then runs on behalf of the test's company like its submission does.
+func (s *service) checkCodingTest(ctx context.Context, o *execOptions) error {
+	test, err := s.codingTests.GetTestByID(ctx, o.testID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: test %s not found", ErrInvalidCodingTest, o.testID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get coding test %s: %w", o.testID, err)
+	}
+
+	if o.tenant > 0 {
+		if test.CompanyID != o.tenant {
+			return fmt.Errorf("%w: test %s not found", ErrInvalidCodingTest, o.testID)
+		}
+		return nil
+	}
+
+	now := time.Now()
+	inProgress := test.Status == models.TestStatusStarted && test.StartedAt != nil &&
+		now.Before(test.StartedAt.Add(time.Duration(test.TestDurationMinutes)*time.Minute)) &&
+		now.Before(test.ExpiresAt)
+	if !inProgress {
+		return fmt.Errorf("%w: test %s is not in progress", ErrInvalidCodingTest, o.testID)
+	}
+	o.tenant = test.CompanyID
+	return nil
+}
+
+// record completes an audit record with the outcome of the execution and
+// stores it. Failing to store it is logged and does not fail the execution.
+func (s *service) record(ctx context.Context, execution *models.Execution, results *models.ExecutionResults, err error) {
+	if s.history == nil {
+		return
+	}
+
+	execution.FinishedAt = time.Now()
+	execution.DurationMs = execution.FinishedAt.Sub(execution.StartedAt).Milliseconds()
+	execution.TestCases = []models.ExecutionTestCase{}
+	if err != nil {
+		execution.Error = err.Error()
+	}
+	if results != nil {
+		execution.Verdict = results.Verdict
+		execution.Cached = results.Cached
+		for _, testResult := range results.TestResults {
+			execution.TestCases = append(execution.TestCases, models.ExecutionTestCase{
+				TestCaseID: testResult.TestCaseID,
+				TestName:   testResult.TestName,
+				Verdict:    testResult.Verdict,
+				Usage:      testResult.Usage,
+			})
+		}
+	}
+
+	// The record is kept even if the client went away mid-run.
+	if err := s.history.CreateExecution(context.WithoutCancel(ctx), execution); err != nil {
+		s.logger.Printf("Failed to record execution of %s: %v", execution.CodeHash, err)
+	}
+}
+
+// recordRun records a single run without test cases, see Execute.
+func (s *service) recordRun(ctx context.Context, execution *models.Execution, result *ExecutionResult, err error) {
+	var results *models.ExecutionResults
+	if result != nil {
+		results = &models.ExecutionResults{Verdict: result.Verdict}
+		if result.Usage != nil {
+			results.TestResults = []models.TestResult{{Verdict: result.Verdict, Usage: result.Usage}}
+		}
+	}
+	s.record(ctx, execution, results, err)
+}
@@ -6,7 +6,11 @@ import (
 )
 
 type Service interface {
-	Execute(ctx context.Context, code string, language string) (*ExecutionResult, error)
-	ExecuteWithTestCases(ctx context.Context, code string, language string, testCases []*models.TestCase) (*models.ExecutionResults, error)
-	ExecuteForProblem(ctx context.Context, code string, language string, problemID int) (*models.ExecutionResults, error)
+	Languages() []Language
+	Language(name string) (*Language, error)
+	Execute(ctx context.Context, code string, language string, opts ...Option) (*ExecutionResult, error)
+	ExecuteWithTestCases(ctx context.Context, code string, language string, testCases []*models.TestCase, opts ...Option) (*models.ExecutionResults, error)
+	ExecuteForProblem(ctx context.Context, code string, language string, problemID int, opts ...Option) (*models.ExecutionResults, error)
+	Analyze(ctx context.Context, code string, language string, opts ...Option) ([]models.Diagnostic, error)
+	Format(ctx context.Context, code string, language string, opts ...Option) (*FormatResult, error)
 }
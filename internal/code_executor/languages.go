@@ -0,0 +1,231 @@
+package code_executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedLanguage is returned when a request names a language that is
+// not present in the registry.
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// Limits describes the resources a single sandboxed run may use.
+type Limits struct {
+	TimeLimit time.Duration
+	MemoryMB  int
+	CPUs      float64
+}
+
+func (l Limits) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		TimeLimitMs int64   `json:"time_limit_ms,omitempty"`
+		MemoryMB    int     `json:"memory_mb"`
+		CPUs        float64 `json:"cpus"`
+	}{
+		TimeLimitMs: l.TimeLimit.Milliseconds(),
+		MemoryMB:    l.MemoryMB,
+		CPUs:        l.CPUs,
+	})
+}
+
+// CacheMount is a host directory (relative to the runbox base dir) that is
+// shared between runs of the same language, e.g. the Go build cache.
+type CacheMount struct {
+	Name   string
+	Target string
+	// PerVersion gives every toolchain version its own cache directory.
+	PerVersion bool
+	// Env names the variable that points the toolchain at the cache, as
+	// sandboxed commands cannot rely on a home directory.
+	Env string
+}
+
+// Language describes how to build and run a submission for one runtime.
+type Language struct {
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Image       string   `json:"image"`
+	SourceFile  string   `json:"source_file"`
+	// Runtime is the OCI runtime of the language's containers, e.g. runsc
+	// for gVisor. Empty uses the daemon's default.
+	Runtime string `json:"-"`
+
+	// Toolchains are the selectable versions, each with its own image.
+	// DefaultVersion is used when a request does not pick one.
+	Toolchains     []Toolchain `json:"toolchains,omitempty"`
+	DefaultVersion string      `json:"default_version,omitempty"`
+
+	// CompileCmd is run once inside the work dir before RunCmd. It is empty
+	// for interpreted languages.
+	CompileCmd string `json:"-"`
+	RunCmd     string `json:"-"`
+
+	// ProjectCompileCmd replaces CompileCmd for multi-file submissions.
+	// Manifest names the project file, e.g. go.mod, that is created from
+	// DefaultManifest when the submission does not include one.
+	ProjectCompileCmd string `json:"-"`
+	Manifest          string `json:"-"`
+	DefaultManifest   string `json:"-"`
+
+	// TestFile, TestCompileCmd and TestRunCmd grade the submission with an
+	// author-supplied test suite; TestRunCmd must print go test -json events.
+	// TestProjectCompileCmd replaces TestCompileCmd for multi-file
+	// submissions. TestSupport files are compiled into the suite next to
+	// TestFile. They are empty for languages without test suite support.
+	TestFile              string            `json:"-"`
+	TestCompileCmd        string            `json:"-"`
+	TestProjectCompileCmd string            `json:"-"`
+	TestRunCmd            string            `json:"-"`
+	TestSupport           map[string]string `json:"-"`
+
+	// Analyzers are the static checks of Service.Analyze, run in order.
+	Analyzers []Analyzer `json:"analyzers,omitempty"`
+	// FormatCmd prints SourceFile in the language's canonical format, see
+	// Service.Format. It is empty for languages without a formatter.
+	FormatCmd string `json:"-"`
+
+	Env    []string     `json:"-"`
+	Caches []CacheMount `json:"-"`
+	// Harness generates main for function problems; nil if unsupported.
+	Harness HarnessFunc `json:"-"`
+	// Dependencies checks third-party imports; nil means no checks.
+	Dependencies DependencyFunc `json:"-"`
+
+	Limits Limits `json:"limits"`
+	// TimeMultiplier scales the time limit of the candidate's program, the
+	// default as well as a problem's, for slower runtimes.
+	TimeMultiplier float64 `json:"time_multiplier,omitempty"`
+}
+
+// Registry holds the languages the executor can run, keyed by name and alias.
+type Registry struct {
+	languages map[string]*Language
+	names     []string
+}
+
+func NewRegistry(languages ...Language) *Registry {
+	r := &Registry{languages: make(map[string]*Language)}
+	for i := range languages {
+		lang := languages[i]
+		r.languages[lang.Name] = &lang
+		r.names = append(r.names, lang.Name)
+		for _, alias := range lang.Aliases {
+			r.languages[alias] = &lang
+		}
+	}
+	sort.Strings(r.names)
+	return r
+}
+
+// Get looks a language up by name or alias.
+func (r *Registry) Get(name string) (*Language, error) {
+	lang, ok := r.languages[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, supported languages: %s", ErrUnsupportedLanguage, name, strings.Join(r.names, ", "))
+	}
+	return lang, nil
+}
+
+// List returns every registered language ordered by name.
+func (r *Registry) List() []Language {
+	languages := make([]Language, 0, len(r.names))
+	for _, name := range r.names {
+		languages = append(languages, *r.languages[name])
+	}
+	return languages
+}
+
+// DefaultRegistry returns the runtimes shipped with the service.
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		Language{
+			Name:        "go",
+			DisplayName: "Go",
+			Aliases:     []string{"golang"},
+			Image:       "golang:1.22-alpine",
+			Toolchains: []Toolchain{
+				{Version: "1.21", Image: "golang:1.21-alpine"},
+				{Version: "1.22", Image: "golang:1.22-alpine"},
+				{Version: "1.23", Image: "golang:1.23-alpine"},
+			},
+			DefaultVersion:    "1.22",
+			SourceFile:        "main.go",
+			CompileCmd:        "go build -o main main.go",
+			RunCmd:            "./main",
+			ProjectCompileCmd: "go build -o main .",
+			Manifest:          "go.mod",
+			DefaultManifest:   "module submission\n\ngo 1.21\n",
+			TestFile:          "main_test.go",
+			// The test binary writes its events to fd 3, see goTestEvents;
+			// the candidate's output goes to stderr.
+			TestCompileCmd:        "go test -c -o main.test main.go " + goTestEventsFile + " main_test.go",
+			TestProjectCompileCmd: "go test -c -o main.test .",
+			TestRunCmd:            `events=$(mktemp) && ./main.test -test.v=test2json -test.paniconexit0 3>"$events" >&2; code=$?; go tool test2json <"$events"; exit $code`,
+			TestSupport:           map[string]string{goTestEventsFile: goTestEvents},
+			Analyzers: []Analyzer{
+				{Name: "gofmt", Cmd: "gofmt -l .", FileMessage: "file is not gofmt-formatted"},
+				{Name: "vet", Cmd: "if [ -f go.mod ]; then go vet ./...; else go vet main.go; fi"},
+			},
+			FormatCmd: "gofmt main.go",
+			// Modules resolve only from the pre-seeded module cache.
+			Env: []string{"GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off", "GOTOOLCHAIN=local"},
+			Caches: []CacheMount{
+				{Name: "go-build-cache", Target: "/cache/go-build", PerVersion: true, Env: "GOCACHE"},
+				{Name: "go-mod-cache", Target: "/go/pkg/mod", Env: "GOMODCACHE"},
+			},
+			Harness:      goHarness,
+			Dependencies: goDependencies,
+			Limits:       Limits{MemoryMB: 256, CPUs: 0.5},
+		},
+		Language{
+			Name:           "python",
+			DisplayName:    "Python 3.12",
+			Aliases:        []string{"py", "python3"},
+			Image:          "python:3.12-alpine",
+			SourceFile:     "main.py",
+			RunCmd:         "python3 main.py",
+			Env:            []string{"PYTHONDONTWRITEBYTECODE=1"},
+			Harness:        pythonHarness,
+			Limits:         Limits{MemoryMB: 256, CPUs: 0.5},
+			TimeMultiplier: 3,
+		},
+		Language{
+			Name:           "javascript",
+			DisplayName:    "JavaScript (Node.js 20)",
+			Aliases:        []string{"js", "node"},
+			Image:          "node:20-alpine",
+			SourceFile:     "main.js",
+			RunCmd:         "node main.js",
+			Harness:        javascriptHarness,
+			Limits:         Limits{MemoryMB: 256, CPUs: 0.5},
+			TimeMultiplier: 2,
+		},
+		Language{
+			Name:              "cpp",
+			DisplayName:       "C++17 (GCC 14)",
+			Aliases:           []string{"c++"},
+			Image:             "gcc:14",
+			SourceFile:        "main.cpp",
+			CompileCmd:        "g++ -O2 -std=c++17 -o main main.cpp",
+			ProjectCompileCmd: "g++ -O2 -std=c++17 -o main $(find . -name '*.cpp')",
+			RunCmd:            "./main",
+			Limits:            Limits{MemoryMB: 256, CPUs: 0.5},
+		},
+		Language{
+			Name:              "java",
+			DisplayName:       "Java 21",
+			Image:             "eclipse-temurin:21-jdk-alpine",
+			SourceFile:        "Main.java",
+			CompileCmd:        "javac Main.java",
+			ProjectCompileCmd: "javac -d . $(find . -name '*.java')",
+			RunCmd:            "java -XX:+UseSerialGC Main",
+			Limits:            Limits{MemoryMB: 512, CPUs: 0.5},
+			TimeMultiplier:    2,
+		},
+	)
+}
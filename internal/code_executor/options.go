@@ -0,0 +1,171 @@
+package code_executor
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"go-code-runner/internal/models"
+)
+
+// EventType identifies the kind of progress Event emitted during an execution.
+type EventType string
+
+const (
+	EventTestCaseStarted  EventType = "test_case_started"
+	EventTestCaseFinished EventType = "test_case_finished"
+	EventOutput           EventType = "output"
+)
+
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// Event reports the progress of an execution to the caller. Index is the
+// position of the test case in the slice passed to ExecuteWithTestCases.
+// Output events carry a chunk of the program's stdout or stderr as soon as
+// it is produced.
+type Event struct {
+	Type       EventType          `json:"type"`
+	Index      int                `json:"index"`
+	TestCaseID int                `json:"test_case_id"`
+	Result     *models.TestResult `json:"result,omitempty"`
+	Stream     string             `json:"stream,omitempty"`
+	Data       string             `json:"data,omitempty"`
+}
+
+// Option customises a single call to the executor.
+type Option func(*execOptions)
+
+// WithEvents registers a callback that receives progress events. Calls are
+// serialised, so fn does not need to be safe for concurrent use.
+func WithEvents(fn func(Event)) Option {
+	return func(o *execOptions) {
+		o.onEvent = fn
+	}
+}
+
+// WithLimits overrides the language's default limits for the candidate's
+// program. Zero fields keep the default. The time limit is scaled by the
+// language's TimeMultiplier.
+func WithLimits(limits Limits) Option {
+	return func(o *execOptions) {
+		o.limits = &limits
+	}
+}
+
+// WithChecker sets how test case outputs are judged. Without it, or with a
+// nil checker, outputs are compared exactly.
+func WithChecker(checker *models.Checker) Option {
+	return func(o *execOptions) {
+		o.checker = checker
+	}
+}
+
+// WithFunctionSignature treats the code as the implementation of sig and
+// wraps it in a generated main before compiling.
+func WithFunctionSignature(sig *models.FunctionSignature) Option {
+	return func(o *execOptions) {
+		o.signature = sig
+	}
+}
+
+// WithFiles adds a file tree (slash-separated relative path to content) to
+// the workspace. Repeated calls merge, later files replacing earlier ones,
+// and a non-empty code argument always replaces the language's source file.
+func WithFiles(files map[string]string) Option {
+	return func(o *execOptions) {
+		if len(files) == 0 {
+			return
+		}
+		if o.files == nil {
+			o.files = make(map[string]string, len(files))
+		}
+		for name, content := range files {
+			o.files[name] = content
+		}
+	}
+}
+
+// WithVersion selects a toolchain version of the language, e.g. "1.21" for
+// Go. Without it the language's default version is used.
+func WithVersion(version string) Option {
+	return func(o *execOptions) {
+		o.version = version
+	}
+}
+
+// WithTenant runs the submission on behalf of a company, applying the
+// runtime the company requires, if any.
+func WithTenant(companyID int) Option {
+	return func(o *execOptions) {
+		o.tenant = companyID
+	}
+}
+
+type execOptions struct {
+	mu        sync.Mutex
+	onEvent   func(Event)
+	limits    *Limits
+	checker   *models.Checker
+	signature *models.FunctionSignature
+	files     map[string]string
+	version   string
+	tenant    int
+	testID    string
+	problemID int
+	execution *models.Execution
+}
+
+func newExecOptions(opts []Option) *execOptions {
+	o := &execOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *execOptions) emit(e Event) {
+	if o.onEvent == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.onEvent(e)
+}
+
+// outputStream forwards the output of one run as EventOutput events.
+type outputStream struct {
+	o          *execOptions
+	index      int
+	testCaseID int
+}
+
+func (o *execOptions) outputStream(index, testCaseID int) *outputStream {
+	return &outputStream{o: o, index: index, testCaseID: testCaseID}
+}
+
+// writer returns buf, teed into output events when a listener is registered.
+func (st *outputStream) writer(stream string, buf *bytes.Buffer) io.Writer {
+	if st == nil || st.o.onEvent == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, &eventWriter{stream: st, name: stream})
+}
+
+type eventWriter struct {
+	stream *outputStream
+	name   string
+}
+
+func (w *eventWriter) Write(p []byte) (int, error) {
+	w.stream.o.emit(Event{
+		Type:       EventOutput,
+		Index:      w.stream.index,
+		TestCaseID: w.stream.testCaseID,
+		Stream:     w.name,
+		Data:       string(p),
+	})
+	return len(p), nil
+}
@@ -0,0 +1,62 @@
+package code_executor
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultMaxOutputBytes caps each output stream of a run unless configured.
+const defaultMaxOutputBytes = 1 << 20
+
+// limitWriter passes the first limit bytes written to it on to w and drops
+// the rest, calling onExceeded once when output is first dropped. Writes
+// never fail, so the sandbox keeps draining the stream until the command is
+// stopped.
+type limitWriter struct {
+	w          io.Writer
+	remaining  int
+	exceeded   bool
+	onExceeded func()
+}
+
+func newLimitWriter(w io.Writer, limit int, onExceeded func()) *limitWriter {
+	return &limitWriter{w: w, remaining: limit, onExceeded: onExceeded}
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if n > l.remaining {
+		p = p[:l.remaining]
+		if !l.exceeded {
+			l.exceeded = true
+			l.onExceeded()
+		}
+	}
+	if len(p) > 0 {
+		l.remaining -= len(p)
+		if _, err := l.w.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// sanitizeOutput makes program output safe to return as JSON and to store
+// in Postgres text columns: a rune cut off by truncation is dropped, other
+// invalid UTF-8 is replaced and NUL bytes are removed.
+func sanitizeOutput(s string, truncated bool) string {
+	if truncated {
+		for i := 0; i < utf8.UTFMax && i < len(s); i++ {
+			end := len(s) - i
+			if utf8.RuneStart(s[end-1]) {
+				if !utf8.FullRuneInString(s[end-1:]) {
+					s = s[:end-1]
+				}
+				break
+			}
+		}
+	}
+	s = strings.ToValidUTF8(s, "�")
+	return strings.ReplaceAll(s, "\x00", "")
+}
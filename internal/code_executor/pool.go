@@ -0,0 +1,40 @@
+package code_executor
+
+import "context"
+
+// sandboxPool caps the number of sandboxes running at the same time across
+// every request handled by the process. Callers over the cap queue in
+// acquire until a slot is released or their context is cancelled.
+type sandboxPool struct {
+	slots chan struct{}
+}
+
+func newSandboxPool(size int) *sandboxPool {
+	if size < 1 {
+		size = 1
+	}
+	return &sandboxPool{slots: make(chan struct{}, size)}
+}
+
+func (p *sandboxPool) acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tryAcquire takes a slot only if one is free right now.
+func (p *sandboxPool) tryAcquire() bool {
+	select {
+	case p.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *sandboxPool) release() {
+	<-p.slots
+}
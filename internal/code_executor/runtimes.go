@@ -0,0 +1,70 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-code-runner/internal/platform/docker"
+)
+
+// ErrRuntimeUnavailable is returned when a language or tenant requires an
+// OCI runtime the sandbox cannot provide.
+var ErrRuntimeUnavailable = errors.New("sandbox runtime unavailable")
+
+// WithRuntime returns the language running under runtime, e.g. runsc for
+// gVisor. An empty runtime keeps the language's own.
+func (l *Language) WithRuntime(runtime string) *Language {
+	if runtime == "" || runtime == l.Runtime {
+		return l
+	}
+	updated := *l
+	updated.Runtime = runtime
+	return &updated
+}
+
+// SetRuntime sets the runtime of a registered language, e.g. from the
+// configuration.
+func (r *Registry) SetRuntime(name string, runtime string) error {
+	lang, err := r.Get(name)
+	if err != nil {
+		return err
+	}
+	lang.Runtime = runtime
+	return nil
+}
+
+// VerifyRuntimes checks that the Docker daemon offers every runtime in
+// required, which maps a runtime to what requires it (e.g. "language go").
+func VerifyRuntimes(ctx context.Context, client *docker.Client, required map[string][]string) error {
+	if len(required) == 0 {
+		return nil
+	}
+
+	available, err := client.Runtimes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list docker runtimes: %w", err)
+	}
+
+	runtimes := make([]string, 0, len(required))
+	for runtime := range required {
+		runtimes = append(runtimes, runtime)
+	}
+	sort.Strings(runtimes)
+
+	for _, runtime := range runtimes {
+		found := false
+		for _, name := range available {
+			found = found || name == runtime
+		}
+		if !found {
+			requiredBy := append([]string(nil), required[runtime]...)
+			sort.Strings(requiredBy)
+			return fmt.Errorf("%w: %s is required by %s but not installed on the docker daemon (available: %s)",
+				ErrRuntimeUnavailable, runtime, strings.Join(requiredBy, ", "), strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
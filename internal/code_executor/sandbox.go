@@ -0,0 +1,63 @@
+package code_executor
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"go-code-runner/internal/models"
+)
+
+// Sandbox runs the commands of a submission in isolation. Implementations
+// must be safe for concurrent use.
+type Sandbox interface {
+	// Prepare makes the language's runtime available, e.g. pulls its image.
+	Prepare(ctx context.Context, lang *Language) error
+	// Run executes req.Script in the workspace and waits for it to finish.
+	// Running out of req.Limits is reported on the RunResult; an error means
+	// the sandbox itself failed or ctx was cancelled.
+	Run(ctx context.Context, req RunRequest) (*RunResult, error)
+}
+
+// RunRequest describes a single command run in a sandbox.
+type RunRequest struct {
+	// RunID identifies the submission in logs.
+	RunID string
+	// Dir is the workspace, used as the working directory of Script.
+	Dir    string
+	Lang   *Language
+	Limits Limits
+	// Script is a shell script, e.g. the language's compile or run command.
+	Script string
+	// StdinFile is the path of a file fed to Script's stdin; empty for none.
+	// It lies outside Dir, and Script must not be able to read other runs'
+	// inputs through it.
+	StdinFile string
+	// Writable lets Script create files in Dir, as compilers must. The
+	// candidate's program gets a read-only workspace where the sandbox can
+	// enforce it.
+	Writable bool
+	// WritableCaches lets Script write to the language's caches, as the
+	// toolchain does when compiling or vetting. Other commands can only
+	// read them where the sandbox can enforce it.
+	WritableCaches bool
+	Stdout         io.Writer
+	Stderr         io.Writer
+}
+
+// Input returns the contents of StdinFile.
+func (r RunRequest) Input() (string, error) {
+	if r.StdinFile == "" {
+		return "", nil
+	}
+	b, err := os.ReadFile(r.StdinFile)
+	return string(b), err
+}
+
+// RunResult is how a sandboxed command ended.
+type RunResult struct {
+	ExitCode  int
+	TimedOut  bool
+	OOMKilled bool
+	Usage     *models.ResourceUsage
+}
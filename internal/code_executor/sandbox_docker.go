@@ -0,0 +1,515 @@
+package code_executor
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go-code-runner/internal/models"
+	"go-code-runner/internal/platform/docker"
+)
+
+const (
+	// containerCleanupTimeout bounds removing a container after its run,
+	// which must happen even when the run's context is already cancelled.
+	containerCleanupTimeout = 30 * time.Second
+	// execExitTimeout bounds waiting for the daemon to record the exit code
+	// of an exec whose output stream already ended.
+	execExitTimeout = time.Second
+)
+
+// sandboxLabel marks sandbox containers with the hostname of the API
+// instance that created them.
+const sandboxLabel = "go-code-runner.sandbox"
+
+// inputMount is where sandbox containers find the stdin of their run.
+const inputMount = "/input"
+
+// idleCmd keeps a sandbox container alive until a command is exec'd into it.
+var idleCmd = []string{"sh", "-c", "while :; do sleep 3600; done"}
+
+// The hardened profile of sandbox containers: commands run as nobody on a
+// read-only root filesystem, with /tmp as the only writable path besides
+// /app and the caches.
+const (
+	sandboxUser         = "65534:65534"
+	sandboxUID          = 65534 // user and group of sandboxUser
+	sandboxTmpfs        = "rw,nosuid,nodev,size=64m"
+	sandboxMaxOpenFiles = 1024
+	sandboxMaxFileSize  = 64 << 20
+	// defaultPidsLimit leaves room for the threads of the Go toolchain and
+	// the JVM, which count against the limit like processes.
+	defaultPidsLimit = 256
+)
+
+// seccompProfile allows the syscalls the language runtimes need and denies
+// the rest, notably namespaces, mounts, ptrace, keyrings, BPF and io_uring.
+//
+//go:embed seccomp.json
+var seccompProfile []byte
+
+// DockerSandboxConfig configures the Docker sandbox.
+type DockerSandboxConfig struct {
+	// WarmPoolSize is the number of started, idle containers kept ready per
+	// language image. Zero starts a container for every run.
+	WarmPoolSize int
+	// WarmPoolMaxIdle is how long an idle container is kept before it is
+	// replaced by a fresh one.
+	WarmPoolMaxIdle time.Duration
+	// PidsLimit caps the processes and threads of a container. Defaults to
+	// 256.
+	PidsLimit int64
+}
+
+// dockerSandbox runs every command in its own container of the language's
+// image with the workspace at /app. Containers serve a single run and are
+// destroyed afterwards.
+type dockerSandbox struct {
+	client      *docker.Client
+	logger      *log.Logger
+	hostTempDir string
+	imageMu     sync.Mutex
+	imageCache  map[string]bool
+	// cacheMu guards cacheOwned, the cache dirs already handed to the
+	// sandbox user by this process.
+	cacheMu    sync.Mutex
+	cacheOwned map[string]bool
+	pool       *warmPool
+	pidsLimit  int64
+	// securityOpt holds no-new-privileges and the seccomp profile.
+	securityOpt []string
+	// instance labels the containers of this API instance.
+	instance string
+}
+
+// sandboxContainer is a started container idling until a command is exec'd
+// into it.
+type sandboxContainer struct {
+	id string
+	// slotDir is mounted at /app instead of the workspace for pooled
+	// containers, which are created before the workspace exists.
+	slotDir string
+	// inputDir is mounted read-only at /input and holds the stdin of the
+	// single run the container serves.
+	inputDir string
+	limits   Limits
+	created  time.Time
+}
+
+// NewDockerSandbox returns the default Sandbox, which runs commands through
+// the Docker Engine API.
+func NewDockerSandbox(client *docker.Client, cfg DockerSandboxConfig, logger *log.Logger) Sandbox {
+	instance, _ := os.Hostname()
+	if cfg.PidsLimit <= 0 {
+		cfg.PidsLimit = defaultPidsLimit
+	}
+
+	// The API takes the profile itself rather than a path, in one line.
+	var profile bytes.Buffer
+	if err := json.Compact(&profile, seccompProfile); err != nil {
+		panic(fmt.Sprintf("invalid seccomp profile: %v", err))
+	}
+
+	d := &dockerSandbox{
+		client:      client,
+		logger:      logger,
+		hostTempDir: hostTempDir(),
+		imageCache:  make(map[string]bool),
+		cacheOwned:  make(map[string]bool),
+		pidsLimit:   cfg.PidsLimit,
+		securityOpt: []string{"no-new-privileges", "seccomp=" + profile.String()},
+		instance:    instance,
+	}
+	if cfg.WarmPoolSize > 0 {
+		d.pool = newWarmPool(d, cfg.WarmPoolSize, cfg.WarmPoolMaxIdle)
+	}
+	return d
+}
+
+// hostTempDir is apiContainerBaseDir as seen by the Docker host, which is
+// where sandbox volume paths are resolved.
+func hostTempDir() string {
+	dir := os.Getenv("HOST_TEMP_DIR") // TODO: Throw error if ENV is not set for HOST_TEMP_DIR
+	if dir == "" {
+		dir = apiContainerBaseDir
+	}
+	return dir
+}
+
+// Prepare pulls the language's image unless it is available locally and
+// creates its cache directories, owned by the unprivileged sandbox user.
+// With a warm pool, it also starts keeping containers of the language ready.
+func (d *dockerSandbox) Prepare(ctx context.Context, lang *Language) error {
+	for _, cache := range lang.Caches {
+		if err := d.prepareCache(filepath.Join(apiContainerBaseDir, cache.Name)); err != nil {
+			return err
+		}
+	}
+
+	if err := d.ensureImage(ctx, lang.Image); err != nil {
+		return err
+	}
+	if d.pool != nil {
+		d.pool.register(lang)
+	}
+	return nil
+}
+
+// prepareCache creates a cache dir and hands it with everything in it to the
+// sandbox user, including entries created as root, e.g. by SeedGoModules.
+// The walk runs once per cache dir and process.
+func (d *dockerSandbox) prepareCache(dir string) error {
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+
+	if d.cacheOwned[dir] {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	// Older versions left the cache dirs world-writable.
+	if err := os.Chmod(dir, 0755); err != nil {
+		return fmt.Errorf("failed to close up cache dir: %w", err)
+	}
+	err := filepath.WalkDir(dir, func(p string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, sandboxUID, sandboxUID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to hand cache dir to the sandbox user: %w", err)
+	}
+	d.cacheOwned[dir] = true
+	return nil
+}
+
+func (d *dockerSandbox) ensureImage(ctx context.Context, imageName string) error {
+	d.imageMu.Lock()
+	defer d.imageMu.Unlock()
+
+	if _, exists := d.imageCache[imageName]; exists {
+		return nil
+	}
+
+	d.logger.Printf("Checking if Docker image %s is available locally...", imageName)
+
+	exists, err := d.client.ImageExists(ctx, imageName)
+	if err != nil {
+		return fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+	if exists {
+		d.logger.Printf("Docker image %s is already available locally", imageName)
+	} else {
+		d.logger.Printf("Docker image %s not found locally, pulling...", imageName)
+		if err := d.client.PullImage(ctx, imageName); err != nil {
+			return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+		}
+		d.logger.Printf("Docker image %s pulled successfully", imageName)
+	}
+
+	d.imageCache[imageName] = true
+	return nil
+}
+
+func (d *dockerSandbox) Run(ctx context.Context, req RunRequest) (result *RunResult, err error) {
+	containerStart := time.Now()
+	c, err := d.acquire(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer d.destroy(req.RunID, c)
+	d.logger.Printf("[%s] Container %.12s ready. (took %v)", req.RunID, c.id, time.Since(containerStart))
+
+	appDir := req.Dir
+	if c.slotDir != "" {
+		appDir = c.slotDir
+		if err := linkTree(req.Dir, c.slotDir); err != nil {
+			return nil, fmt.Errorf("failed to link workspace: %w", err)
+		}
+		// Build output and other new files go back to the workspace for the
+		// next runs of the submission.
+		defer func() {
+			if err := linkTree(c.slotDir, req.Dir); err != nil {
+				d.logger.Printf("[%s] Failed to link files back to the workspace: %v", req.RunID, err)
+			}
+		}()
+	}
+	if err := setWorkspaceAccess(appDir, req.Writable); err != nil {
+		return nil, err
+	}
+	if req.Writable {
+		// Once the command and whatever it left running are gone, see
+		// usageScript, its output is taken back from the sandbox user so
+		// that later commands cannot rewrite it.
+		defer func() {
+			if reclaimErr := reclaimWorkspace(appDir); reclaimErr != nil && err == nil {
+				result, err = nil, reclaimErr
+			}
+		}()
+	}
+
+	script := req.Script
+	if req.StdinFile != "" {
+		// The container only sees a copy in its own input mount, which the
+		// sandbox user may read.
+		stdinPath := filepath.Join(c.inputDir, "stdin")
+		if err := copyFile(req.StdinFile, stdinPath); err != nil {
+			return nil, fmt.Errorf("failed to pass input: %w", err)
+		}
+		if err := os.Chmod(stdinPath, 0644); err != nil {
+			return nil, fmt.Errorf("failed to pass input: %w", err)
+		}
+		script += " < " + inputMount + "/stdin"
+	}
+	execID, err := d.client.CreateExec(ctx, c.id, &docker.ExecConfig{
+		Cmd:          []string{"sh", "-c", script},
+		WorkingDir:   "/app",
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, req.Limits.TimeLimit)
+	defer cancel()
+
+	execStart := time.Now()
+	streamErr := d.client.StartExec(execCtx, execID, req.Stdout, req.Stderr)
+	duration := time.Since(execStart)
+	d.logger.Printf("[%s] Command in container %.12s finished. (took %v)", req.RunID, c.id, duration)
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if execCtx.Err() == context.DeadlineExceeded {
+		// Destroying the container stops the command.
+		d.logger.Printf("[%s] CONTEXT DEADLINE EXCEEDED. Total execution time: %v", req.RunID, duration)
+		return &RunResult{TimedOut: true, ExitCode: -1}, nil
+	}
+	if streamErr != nil {
+		return nil, fmt.Errorf("failed to read command output: %w", streamErr)
+	}
+
+	exitCode, err := d.execExitCode(ctx, execID)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &RunResult{ExitCode: exitCode}
+	if exitCode != 0 {
+		state, err := d.client.InspectContainer(ctx, c.id)
+		if err != nil {
+			d.logger.Printf("[%s] Failed to inspect container %.12s: %v", req.RunID, c.id, err)
+		} else {
+			result.OOMKilled = state.OOMKilled
+		}
+	}
+	result.Usage = d.usage(ctx, req.RunID, c)
+	result.Usage.WallTimeMs = duration.Milliseconds()
+
+	return result, nil
+}
+
+// usage reads the CPU time and peak memory of a container whose command
+// exited with usageScript. Nothing the command could write is trusted: the
+// values come straight from the container's cgroup, and the wall time is
+// measured by the caller around the exec. Usage the sandbox could not read
+// is left at zero.
+func (d *dockerSandbox) usage(ctx context.Context, runID string, c *sandboxContainer) *models.ResourceUsage {
+	execID, err := d.client.CreateExec(ctx, c.id, &docker.ExecConfig{
+		Cmd:          []string{"sh", "-c", usageScript},
+		AttachStdout: true,
+	})
+	if err != nil {
+		d.logger.Printf("[%s] Failed to read usage of container %.12s: %v", runID, c.id, err)
+		return &models.ResourceUsage{}
+	}
+
+	var out bytes.Buffer
+	if err := d.client.StartExec(ctx, execID, &out, io.Discard); err != nil {
+		d.logger.Printf("[%s] Failed to read usage of container %.12s: %v", runID, c.id, err)
+		return &models.ResourceUsage{}
+	}
+	return parseUsage(out.String())
+}
+
+// execExitCode waits for the daemon to record that the exec exited, which
+// can lag slightly behind the end of its output stream.
+func (d *dockerSandbox) execExitCode(ctx context.Context, execID string) (int, error) {
+	deadline := time.Now().Add(execExitTimeout)
+	for {
+		state, err := d.client.InspectExec(ctx, execID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to inspect exec: %w", err)
+		}
+		if !state.Running {
+			return state.ExitCode, nil
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("exec %.12s still running after its output ended", execID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// acquire returns a started container for req with its limits applied,
+// taken from the warm pool when one is ready.
+func (d *dockerSandbox) acquire(ctx context.Context, req RunRequest) (*sandboxContainer, error) {
+	var c *sandboxContainer
+	if d.pool != nil {
+		c = d.pool.take(req.Lang, req.WritableCaches)
+	}
+	if c == nil {
+		if d.pool != nil {
+			d.logger.Printf("[%s] No warm container for %s, starting one", req.RunID, req.Lang.Image)
+		}
+		return d.startContainer(ctx, req.Lang, req.Limits, req.Dir, req.WritableCaches)
+	}
+
+	if c.limits.MemoryMB != req.Limits.MemoryMB || c.limits.CPUs != req.Limits.CPUs {
+		if err := d.client.UpdateContainer(ctx, c.id, d.containerResources(req.Limits)); err != nil {
+			d.destroy(req.RunID, c)
+			return nil, fmt.Errorf("failed to apply limits to container: %w", err)
+		}
+		c.limits = req.Limits
+	}
+	return c, nil
+}
+
+// startContainer creates and starts an idle container with workspaceDir
+// mounted at /app. Without a workspace, as for warm containers that do not
+// know their run yet, a fresh slot directory is mounted instead. The caches
+// are mounted read-only unless writableCaches is set.
+func (d *dockerSandbox) startContainer(ctx context.Context, lang *Language, limits Limits, workspaceDir string, writableCaches bool) (*sandboxContainer, error) {
+	c := &sandboxContainer{limits: limits, created: time.Now()}
+	mountDir := workspaceDir
+	if mountDir == "" {
+		c.slotDir = newSlotDir()
+		if err := os.MkdirAll(c.slotDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create slot dir: %w", err)
+		}
+		mountDir = c.slotDir
+	}
+	c.inputDir = filepath.Join(apiContainerBaseDir, "inputs", uuid.New().String())
+	if err := os.MkdirAll(c.inputDir, 0755); err != nil {
+		os.RemoveAll(c.slotDir)
+		return nil, fmt.Errorf("failed to create input dir: %w", err)
+	}
+
+	cfg := &docker.ContainerConfig{
+		Image:      lang.Image,
+		Cmd:        idleCmd,
+		Env:        append([]string{"HOME=/tmp"}, lang.Env...),
+		WorkingDir: "/app",
+		Labels:     map[string]string{sandboxLabel: d.instance},
+		User:       sandboxUser,
+		HostConfig: docker.HostConfig{
+			Binds:          []string{d.toHostPath(mountDir) + ":/app"},
+			NetworkMode:    "none",
+			Runtime:        lang.Runtime,
+			ReadonlyRootfs: true,
+			Tmpfs:          map[string]string{"/tmp": sandboxTmpfs},
+			CapDrop:        []string{"ALL"},
+			SecurityOpt:    d.securityOpt,
+			Ulimits: []docker.Ulimit{
+				{Name: "nofile", Soft: sandboxMaxOpenFiles, Hard: sandboxMaxOpenFiles},
+				{Name: "fsize", Soft: sandboxMaxFileSize, Hard: sandboxMaxFileSize},
+			},
+			Resources: d.containerResources(limits),
+		},
+	}
+	cacheMode := "ro"
+	if writableCaches {
+		cacheMode = "rw"
+	}
+	for _, cache := range lang.Caches {
+		hostCacheDir := filepath.Join(d.hostTempDir, cache.Name)
+		cfg.HostConfig.Binds = append(cfg.HostConfig.Binds, fmt.Sprintf("%s:%s:%s", hostCacheDir, cache.Target, cacheMode))
+		if cache.Env != "" {
+			cfg.Env = append(cfg.Env, cache.Env+"="+cache.Target)
+		}
+	}
+	cfg.HostConfig.Binds = append(cfg.HostConfig.Binds, d.toHostPath(c.inputDir)+":"+inputMount+":ro")
+
+	id, err := d.client.CreateContainer(ctx, "runbox-"+uuid.New().String(), cfg)
+	if err != nil {
+		os.RemoveAll(c.slotDir)
+		os.RemoveAll(c.inputDir)
+		return nil, fmt.Errorf("failed to create container: %w", err)
+	}
+	c.id = id
+
+	if err := d.client.StartContainer(ctx, id); err != nil {
+		d.destroy("", c)
+		return nil, fmt.Errorf("failed to start container: %w", err)
+	}
+	return c, nil
+}
+
+// removeStale removes containers a previous run of this API instance left
+// behind, e.g. warm containers when it was stopped.
+func (d *dockerSandbox) removeStale(ctx context.Context) {
+	ids, err := d.client.ListContainers(ctx, sandboxLabel+"="+d.instance)
+	if err != nil {
+		d.logger.Printf("Failed to list stale sandbox containers: %v", err)
+		return
+	}
+	for _, id := range ids {
+		d.destroy("", &sandboxContainer{id: id})
+	}
+	os.RemoveAll(filepath.Join(apiContainerBaseDir, "slots"))
+	os.RemoveAll(filepath.Join(apiContainerBaseDir, "inputs"))
+	if len(ids) > 0 {
+		d.logger.Printf("Removed %d stale sandbox containers", len(ids))
+	}
+}
+
+// destroy removes the container and its slot and input directories.
+func (d *dockerSandbox) destroy(runID string, c *sandboxContainer) {
+	ctx, cancel := context.WithTimeout(context.Background(), containerCleanupTimeout)
+	defer cancel()
+	if err := d.client.RemoveContainer(ctx, c.id); err != nil {
+		d.logger.Printf("[%s] Failed to remove container %.12s: %v", runID, c.id, err)
+	}
+	if c.slotDir != "" {
+		os.RemoveAll(c.slotDir)
+	}
+	if c.inputDir != "" {
+		os.RemoveAll(c.inputDir)
+	}
+}
+
+// containerResources converts limits to the daemon's resources. Swap is
+// capped like `docker run --memory` does by default.
+func (d *dockerSandbox) containerResources(limits Limits) docker.Resources {
+	memory := int64(limits.MemoryMB) << 20
+	return docker.Resources{
+		Memory:     memory,
+		MemorySwap: 2 * memory,
+		NanoCPUs:   docker.NanoCPUs(limits.CPUs),
+		PidsLimit:  d.pidsLimit,
+	}
+}
+
+func newSlotDir() string {
+	return filepath.Join(apiContainerBaseDir, "slots", uuid.New().String())
+}
+
+// toHostPath maps a path below apiContainerBaseDir to the Docker host.
+func (d *dockerSandbox) toHostPath(dir string) string {
+	return strings.Replace(dir, apiContainerBaseDir, d.hostTempDir, 1)
+}
@@ -0,0 +1,69 @@
+package code_executor
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"go-code-runner/internal/models"
+)
+
+// FakeSandbox is a scripted Sandbox for tests. Every run is recorded and
+// answered by Handler; without a Handler runs exit cleanly with no output.
+type FakeSandbox struct {
+	Handler func(req RunRequest) FakeRun
+
+	mu   sync.Mutex
+	runs []RunRequest
+}
+
+// FakeRun is the scripted outcome of a single FakeSandbox run.
+type FakeRun struct {
+	Stdout    string
+	Stderr    string
+	ExitCode  int
+	TimedOut  bool
+	OOMKilled bool
+	Usage     *models.ResourceUsage
+	// Err fails the run as if the sandbox itself broke.
+	Err error
+}
+
+func (f *FakeSandbox) Prepare(ctx context.Context, lang *Language) error {
+	return nil
+}
+
+func (f *FakeSandbox) Run(ctx context.Context, req RunRequest) (*RunResult, error) {
+	f.mu.Lock()
+	f.runs = append(f.runs, req)
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var run FakeRun
+	if f.Handler != nil {
+		run = f.Handler(req)
+	}
+	if run.Err != nil {
+		return nil, run.Err
+	}
+
+	io.WriteString(req.Stdout, run.Stdout)
+	io.WriteString(req.Stderr, run.Stderr)
+
+	return &RunResult{
+		ExitCode:  run.ExitCode,
+		TimedOut:  run.TimedOut,
+		OOMKilled: run.OOMKilled,
+		Usage:     run.Usage,
+	}, nil
+}
+
+// Runs returns the requests received so far.
+func (f *FakeSandbox) Runs() []RunRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]RunRequest(nil), f.runs...)
+}
@@ -0,0 +1,269 @@
+//go:build unix
+
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-code-runner/internal/models"
+)
+
+// LocalSandboxConfig configures the local-process sandbox.
+type LocalSandboxConfig struct {
+	// UID and GID run commands as a separate, unprivileged user. Zero keeps
+	// the user of the API process; anything else requires running as root.
+	UID int
+	GID int
+	// MaxProcesses caps the processes of the sandbox user. It is only
+	// applied together with UID, as the limit counts every process of the
+	// user.
+	MaxProcesses int
+	// MaxFileSizeMB caps the size of every file a command writes.
+	MaxFileSizeMB int
+}
+
+const (
+	defaultLocalMaxProcesses  = 64
+	defaultLocalMaxFileSizeMB = 64
+	// localWaitDelay is how long background processes left behind by a
+	// command may keep its output open before they are killed.
+	localWaitDelay = 100 * time.Millisecond
+)
+
+// localSandbox runs commands as plain child processes restricted by
+// rlimits. It isolates far less than a container and is meant for dev
+// machines and CI without a Docker daemon; the language toolchains must be
+// installed on the host.
+type localSandbox struct {
+	cfg    LocalSandboxConfig
+	logger *log.Logger
+}
+
+// NewLocalSandbox returns a Sandbox that runs commands on the host.
+func NewLocalSandbox(cfg LocalSandboxConfig, logger *log.Logger) (Sandbox, error) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		return nil, fmt.Errorf("local sandbox: %w", err)
+	}
+	if cfg.UID != 0 && os.Geteuid() != 0 {
+		return nil, errors.New("local sandbox: running as a separate user requires root")
+	}
+	if cfg.MaxProcesses <= 0 {
+		cfg.MaxProcesses = defaultLocalMaxProcesses
+	}
+	if cfg.MaxFileSizeMB <= 0 {
+		cfg.MaxFileSizeMB = defaultLocalMaxFileSizeMB
+	}
+	l := &localSandbox{cfg: cfg, logger: logger}
+	if cfg.UID == 0 {
+		logger.Printf("warning: local sandbox runs commands as the API user, the process limit of %d is not enforced", cfg.MaxProcesses)
+	} else if err := exec.Command("sh", "-c", l.processLimit()).Run(); err != nil {
+		logger.Printf("warning: local sandbox cannot set the process limit of %d: %v", cfg.MaxProcesses, err)
+	}
+	return l, nil
+}
+
+// Prepare creates the language's cache directories, owned by the sandbox
+// user so the toolchain can write to them.
+func (l *localSandbox) Prepare(ctx context.Context, lang *Language) error {
+	if lang.Runtime != "" {
+		return fmt.Errorf("%w: the local sandbox cannot run %s under %s", ErrRuntimeUnavailable, lang.Name, lang.Runtime)
+	}
+	for _, cache := range lang.Caches {
+		dir := filepath.Join(apiContainerBaseDir, cache.Name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create cache dir: %w", err)
+		}
+		if err := l.chown(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *localSandbox) Run(ctx context.Context, req RunRequest) (result *RunResult, err error) {
+	// As in a container, the sandbox user only gets to create files in the
+	// workspace while compiling and never owns what is in it.
+	if l.cfg.UID != 0 {
+		if err := setWorkspaceAccess(req.Dir, req.Writable); err != nil {
+			return nil, err
+		}
+		if req.Writable {
+			// Runs after the command's process group is killed below.
+			defer func() {
+				if reclaimErr := reclaimWorkspace(req.Dir); reclaimErr != nil && err == nil {
+					result, err = nil, reclaimErr
+				}
+			}()
+		}
+	}
+
+	// HOME and TMPDIR get a directory of their own, as the workspace is
+	// read-only for most commands.
+	home, err := os.MkdirTemp(apiContainerBaseDir, "home-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create home dir: %w", err)
+	}
+	defer os.RemoveAll(home)
+	if err := l.chown(home); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("sh", "-c", l.limitScript(req.Limits)+req.Script)
+	cmd.Dir = req.Dir
+	cmd.Env = l.env(req, home)
+	cmd.Stdout = req.Stdout
+	cmd.Stderr = req.Stderr
+	// A process group of its own lets a timeout kill everything the
+	// command started.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.WaitDelay = localWaitDelay
+	if l.cfg.UID != 0 {
+		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(l.cfg.UID), Gid: uint32(l.cfg.GID)}
+	}
+
+	if req.StdinFile != "" {
+		// Opened by the API user, the command never gets the path.
+		stdin, err := os.Open(req.StdinFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input: %w", err)
+		}
+		defer stdin.Close()
+		cmd.Stdin = stdin
+	}
+
+	l.logger.Printf("[%s] Executing local command in %s: %s", req.RunID, req.Dir, req.Script)
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	// Whatever is left of the group once the command ends is killed too.
+	defer syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	timer := time.NewTimer(req.Limits.TimeLimit)
+	defer timer.Stop()
+
+	result = &RunResult{}
+	select {
+	case err = <-done:
+	case <-timer.C:
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		err = <-done
+		result.TimedOut = true
+	case <-ctx.Done():
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+		return nil, ctx.Err()
+	}
+	wall := time.Since(start)
+	l.logger.Printf("[%s] Local command finished. (took %v)", req.RunID, wall)
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+		return nil, err
+	}
+
+	result.ExitCode = cmd.ProcessState.ExitCode()
+	if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() && status.Signal() == syscall.SIGXCPU {
+		// The CPU rlimit is a backstop for the wall-clock timer.
+		result.TimedOut = true
+	}
+	if result.TimedOut {
+		result.ExitCode = -1
+	}
+	result.Usage = processUsage(cmd.ProcessState, wall)
+
+	return result, nil
+}
+
+// limitScript sets the rlimits of the shell, inherited by everything it
+// runs. Memory is capped through the data segment, which counts committed
+// rather than resident memory; runtimes commit in large chunks, so the limit
+// gets twice the headroom of a container. Running out of it fails an
+// allocation, which the program reports like any other crash, so the local
+// sandbox reports a runtime error where a container reports a memory limit
+// exceeded.
+func (l *localSandbox) limitScript(limits Limits) string {
+	var b strings.Builder
+	if limits.TimeLimit > 0 {
+		fmt.Fprintf(&b, "ulimit -t %d; ", int(math.Ceil(limits.TimeLimit.Seconds()))+1)
+	}
+	if limits.MemoryMB > 0 {
+		fmt.Fprintf(&b, "ulimit -d %d; ", 2*limits.MemoryMB*1024)
+	}
+	fmt.Fprintf(&b, "ulimit -f %d; ", l.cfg.MaxFileSizeMB*1024)
+	if l.cfg.UID != 0 {
+		b.WriteString(l.processLimit() + "; ")
+	}
+	return b.String()
+}
+
+// processLimit caps the processes of the sandbox user; dash calls the limit
+// -p, bash and busybox -u.
+func (l *localSandbox) processLimit() string {
+	return fmt.Sprintf("ulimit -u %[1]d 2>/dev/null || ulimit -p %[1]d", l.cfg.MaxProcesses)
+}
+
+// env is a minimal environment with HOME and TMPDIR in home.
+func (l *localSandbox) env(req RunRequest, home string) []string {
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + home,
+		"TMPDIR=" + home,
+	}
+	for _, cache := range req.Lang.Caches {
+		if cache.Env != "" {
+			env = append(env, cache.Env+"="+filepath.Join(apiContainerBaseDir, cache.Name))
+		}
+	}
+	return append(env, req.Lang.Env...)
+}
+
+// chown hands dir and everything in it over to the sandbox user.
+func (l *localSandbox) chown(dir string) error {
+	if l.cfg.UID == 0 {
+		return nil
+	}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, l.cfg.UID, l.cfg.GID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to chown %s: %w", dir, err)
+	}
+	return nil
+}
+
+// processUsage reads the rusage of the shell, which includes every child
+// it waited for.
+func processUsage(state *os.ProcessState, wall time.Duration) *models.ResourceUsage {
+	usage := &models.ResourceUsage{WallTimeMs: wall.Milliseconds()}
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return usage
+	}
+	usage.CPUTimeMs = (rusage.Utime.Nano() + rusage.Stime.Nano()) / int64(time.Millisecond)
+	usage.PeakMemoryKB = int64(rusage.Maxrss)
+	if runtime.GOOS == "darwin" {
+		// Reported in bytes rather than kilobytes.
+		usage.PeakMemoryKB /= 1024
+	}
+	return usage
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package code_executor
+
+import (
+	"errors"
+	"log"
+)
+
+// LocalSandboxConfig configures the local-process sandbox.
+type LocalSandboxConfig struct {
+	UID           int
+	GID           int
+	MaxProcesses  int
+	MaxFileSizeMB int
+}
+
+// NewLocalSandbox is only available on Unix systems.
+func NewLocalSandbox(cfg LocalSandboxConfig, logger *log.Logger) (Sandbox, error) {
+	return nil, errors.New("local sandbox is not supported on this platform")
+}
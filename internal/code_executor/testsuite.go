@@ -0,0 +1,310 @@
+package code_executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go-code-runner/internal/models"
+)
+
+// testEvent is a single line of go test -json output.
+type testEvent struct {
+	Action  string  `json:"Action"`
+	Test    string  `json:"Test"`
+	Output  string  `json:"Output"`
+	Elapsed float64 `json:"Elapsed"`
+}
+
+// goTestEventsFile is compiled into Go test suites ahead of the problem's
+// test file so that its setup test runs first.
+const goTestEventsFile = "events_test.go"
+
+// goTestEventsSetup is the setup test of goTestEvents; it is not reported.
+const goTestEventsSetup = "Test__events"
+
+// goTestEvents moves the test2json stream of a Go test binary off stdout so
+// that the candidate's output is not mistaken for events: init hands fd 3 to
+// the testing package, which keeps the os.Stdout of the time for its output,
+// and the setup test gives the real stdout and stderr back to the candidate's
+// code. The candidate's code runs in the same process and can still write to
+// fd 3, so parseTestEvents only trusts events of the tests the problem
+// declares and fails those that never report.
+const goTestEvents = `package main
+
+import (
+	"os"
+	"testing"
+)
+
+var __eventsStdout, __eventsStderr = os.Stdout, os.Stderr
+
+func init() {
+	os.Stdout = os.NewFile(3, "events")
+}
+
+func ` + goTestEventsSetup + `(t *testing.T) {
+	os.Stdout, os.Stderr = __eventsStdout, __eventsStderr
+}
+`
+
+// executeTestSuite compiles the submission together with the problem's test
+// file and reports one TestResult per top-level test function.
+func (s *service) executeTestSuite(ctx context.Context, code string, language string, testFile string, opts ...Option) (results *models.ExecutionResults, err error) {
+	o := newExecOptions(opts)
+
+	execution := o.execution
+	if execution == nil {
+		execution = newExecution(codeHash(code, o.files), language, o)
+		defer func() { s.record(ctx, execution, results, err) }()
+	}
+
+	lang, err := s.resolveExecution(ctx, execution, language, o)
+	if err != nil {
+		return nil, err
+	}
+	if lang.TestFile == "" {
+		return nil, fmt.Errorf("%w %q for test suite problems", ErrUnsupportedLanguage, lang.Name)
+	}
+
+	// Submitted tests would run inside the suite, and a TestMain of theirs
+	// would replace it.
+	for name := range o.files {
+		if strings.HasSuffix(name, "_test.go") {
+			return nil, fmt.Errorf("%w: test file %q not allowed in test suite problems", ErrInvalidFiles, name)
+		}
+	}
+
+	var files map[string]string
+	code, lang, files, err = s.prepareFiles(code, lang, o.files)
+	if errors.Is(err, ErrDisallowedImport) {
+		return &models.ExecutionResults{
+			Success:      false,
+			Verdict:      models.VerdictCompilationError,
+			CompileError: err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The suite runs through the regular compile and run steps with the
+	// test commands swapped in.
+	suite := *lang
+	suite.CompileCmd = lang.TestCompileCmd
+	if len(files) > 0 && lang.TestProjectCompileCmd != "" {
+		suite.CompileCmd = lang.TestProjectCompileCmd
+	}
+	suite.RunCmd = lang.TestRunCmd
+
+	if err := s.sandbox.Prepare(ctx, &suite); err != nil {
+		return nil, err
+	}
+
+	ws, err := s.newWorkspace(code, &suite, files)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.remove()
+
+	testSources := map[string]string{suite.TestFile: testFile}
+	for name, content := range suite.TestSupport {
+		testSources[name] = content
+	}
+	if err := ws.writeFiles(testSources); err != nil {
+		return nil, err
+	}
+
+	compileError, err := s.compile(ctx, ws, &suite)
+	if err != nil {
+		return nil, err
+	}
+	if compileError != "" {
+		return &models.ExecutionResults{
+			Success:      false,
+			Verdict:      models.VerdictCompilationError,
+			CompileError: compileError,
+			Diagnostics:  parseCompileDiagnostics(compileError),
+		}, nil
+	}
+
+	// The hidden tests are compiled into the binary; the candidate's code
+	// must not be able to read them while it runs.
+	for name := range testSources {
+		if err := os.Remove(filepath.Join(ws.dir, name)); err != nil {
+			return nil, fmt.Errorf("failed to remove test file: %w", err)
+		}
+	}
+
+	expected, err := goTestNames(testFile)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.run(ctx, ws, &suite, s.runLimits(lang, o), "tests", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	testResults := parseTestEvents(result, expected)
+	success := true
+	for i := range testResults {
+		if !testResults[i].Passed {
+			success = false
+		}
+		o.emit(Event{Type: EventTestCaseFinished, Index: i, Result: &testResults[i]})
+	}
+
+	return &models.ExecutionResults{
+		Success:     success,
+		Verdict:     overallVerdict(testResults),
+		TestResults: testResults,
+	}, nil
+}
+
+// goTestNames lists the top-level tests of a Go test file in source order.
+func goTestNames(src string) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse test file: %w", err)
+	}
+
+	var names []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !isGoTestName(fn.Name.Name) || len(fn.Type.Params.List) != 1 {
+			continue
+		}
+		// TestMain takes a *testing.M and is not a test.
+		if star, ok := fn.Type.Params.List[0].Type.(*ast.StarExpr); ok {
+			if sel, ok := star.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "T" {
+				names = append(names, fn.Name.Name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// isGoTestName reports whether go test runs a function of that name: Test
+// followed by nothing or by anything but a lower-case letter.
+func isGoTestName(name string) bool {
+	rest, ok := strings.CutPrefix(name, "Test")
+	if !ok {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || !unicode.IsLower(r)
+}
+
+// parseTestEvents turns the go test -json stream of a run into results for
+// the expected top-level tests, in their order. Expected tests that never
+// finished get the verdict of the run, e.g. a time limit exceeded, or a
+// runtime error if the run ended without them. Events of any other test can
+// only have been written by the candidate's code and fail the suite.
+func parseTestEvents(result *ExecutionResult, expected []string) []models.TestResult {
+	var (
+		testResults = make([]models.TestResult, len(expected))
+		index       = make(map[string]int)
+		output      = make(map[string]*strings.Builder)
+		unexpected  []string
+	)
+	for i, name := range expected {
+		index[name] = i
+		output[name] = &strings.Builder{}
+		testResults[i].TestName = name
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(result.Output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Test == "" || event.Test == goTestEventsSetup {
+			continue
+		}
+
+		// Subtests are reported as part of their top-level test.
+		name, _, isSubtest := strings.Cut(event.Test, "/")
+
+		i, ok := index[name]
+		if !ok {
+			index[name] = -1
+			unexpected = append(unexpected, name)
+			continue
+		}
+		if i < 0 {
+			continue
+		}
+
+		switch event.Action {
+		case "output":
+			// Drop the "=== RUN" and "--- FAIL" framing lines.
+			line := strings.TrimSpace(event.Output)
+			if !strings.HasPrefix(line, "=== ") && !strings.HasPrefix(line, "--- ") {
+				output[name].WriteString(event.Output)
+			}
+		case "pass", "skip", "fail":
+			if isSubtest {
+				continue
+			}
+			testResults[i].Verdict = models.VerdictAccepted
+			if event.Action == "fail" {
+				testResults[i].Verdict = models.VerdictWrongAnswer
+			}
+			testResults[i].Usage = &models.ResourceUsage{WallTimeMs: int64(event.Elapsed * 1000)}
+		}
+	}
+
+	for i := range testResults {
+		testResult := &testResults[i]
+		if testResult.Verdict == "" {
+			testResult.Verdict = result.Verdict
+			testResult.Truncated = result.Truncated
+			testResult.Error = result.Error
+			if testResult.Verdict == models.VerdictAccepted {
+				testResult.Verdict = models.VerdictRuntimeError
+				testResult.Error = "test did not run to completion"
+			}
+		}
+		if testResult.Verdict != models.VerdictAccepted {
+			if out := strings.TrimSpace(output[testResult.TestName].String()); out != "" {
+				testResult.Error = strings.TrimSpace(out + "\n" + testResult.Error)
+			}
+		}
+		testResult.Passed = testResult.Verdict == models.VerdictAccepted
+	}
+
+	for _, name := range unexpected {
+		testResults = append(testResults, models.TestResult{
+			TestName: name,
+			Verdict:  models.VerdictRuntimeError,
+			Error:    "unexpected test in the test output",
+		})
+	}
+
+	if len(testResults) == 0 {
+		verdict := result.Verdict
+		message := result.Error
+		if verdict == models.VerdictAccepted {
+			verdict = models.VerdictInternalError
+			message = "test suite contains no tests"
+		}
+		testResults = append(testResults, models.TestResult{
+			Verdict:      verdict,
+			Error:        message,
+			ActualOutput: strings.TrimSpace(result.Output),
+			Truncated:    result.Truncated,
+		})
+	}
+
+	return testResults
+}
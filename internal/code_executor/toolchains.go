@@ -0,0 +1,93 @@
+package code_executor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedVersion is returned when a request or problem names a
+// toolchain version the language does not offer.
+var ErrUnsupportedVersion = errors.New("unsupported language version")
+
+// Toolchain is one selectable version of a language runtime.
+type Toolchain struct {
+	Version string `json:"version"`
+	Image   string `json:"image"`
+}
+
+// WithVersion returns the language pinned to a toolchain version, or to the
+// default version when version is empty. Per-version caches get their own
+// directory. Languages without toolchains only accept an empty version.
+func (l *Language) WithVersion(version string) (*Language, error) {
+	if len(l.Toolchains) == 0 {
+		if version != "" {
+			return nil, fmt.Errorf("%w: %s has no selectable versions", ErrUnsupportedVersion, l.Name)
+		}
+		return l, nil
+	}
+
+	if version == "" {
+		version = l.DefaultVersion
+	}
+
+	for _, toolchain := range l.Toolchains {
+		if toolchain.Version != version {
+			continue
+		}
+
+		pinned := *l
+		pinned.Image = toolchain.Image
+		pinned.DefaultVersion = version
+		pinned.Caches = make([]CacheMount, len(l.Caches))
+		for i, cache := range l.Caches {
+			if cache.PerVersion {
+				cache.Name += "-" + version
+			}
+			pinned.Caches[i] = cache
+		}
+		return &pinned, nil
+	}
+
+	return nil, fmt.Errorf("%w %q for %s, available versions: %s", ErrUnsupportedVersion, version, l.Name, strings.Join(l.Versions(), ", "))
+}
+
+// Versions lists the selectable toolchain versions.
+func (l *Language) Versions() []string {
+	versions := make([]string, len(l.Toolchains))
+	for i, toolchain := range l.Toolchains {
+		versions[i] = toolchain.Version
+	}
+	return versions
+}
+
+// SetToolchains replaces the toolchains of a registered language, e.g. from
+// the configuration. An empty defaultVersion keeps the current default if it
+// is still offered and picks the last toolchain otherwise.
+func (r *Registry) SetToolchains(name string, toolchains []Toolchain, defaultVersion string) error {
+	lang, err := r.Get(name)
+	if err != nil {
+		return err
+	}
+	if len(toolchains) == 0 {
+		return fmt.Errorf("no toolchains given for %s", name)
+	}
+
+	updated := *lang
+	updated.Toolchains = toolchains
+	updated.DefaultVersion = defaultVersion
+	if defaultVersion == "" {
+		updated.DefaultVersion = toolchains[len(toolchains)-1].Version
+		for _, toolchain := range toolchains {
+			if toolchain.Version == lang.DefaultVersion {
+				updated.DefaultVersion = lang.DefaultVersion
+			}
+		}
+	}
+	if _, err := updated.WithVersion(""); err != nil {
+		return fmt.Errorf("invalid default version for %s: %w", name, err)
+	}
+
+	*lang = updated
+	return nil
+}
@@ -0,0 +1,62 @@
+package code_executor
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-code-runner/internal/models"
+)
+
+// usageMarker starts the values printed by usageScript.
+const usageMarker = "usage"
+
+// usageScript is exec'd into a container once its command exited and prints
+// the container's CPU time and peak memory from its cgroup (v2, falling
+// back to v1). It first kills whatever the command left running, so that
+// nothing the candidate started can write to its output after the marker.
+const usageScript = `kill -9 -1 2>/dev/null
+echo; echo ` + usageMarker + `
+if [ -f /sys/fs/cgroup/cpu.stat ]; then
+  echo "cpu_usec $(sed -n 's/^usage_usec //p' /sys/fs/cgroup/cpu.stat)"
+  echo "memory_peak $(cat /sys/fs/cgroup/memory.peak 2>/dev/null)"
+else
+  echo "cpu_nsec $(cat /sys/fs/cgroup/cpuacct/cpuacct.usage 2>/dev/null)"
+  echo "memory_peak $(cat /sys/fs/cgroup/memory/memory.max_usage_in_bytes 2>/dev/null)"
+fi`
+
+// parseUsage parses the output of usageScript; only the lines after its last
+// marker count. Values the sandbox could not provide are left at zero.
+func parseUsage(output string) *models.ResourceUsage {
+	var usage models.ResourceUsage
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == usageMarker {
+			usage = models.ResourceUsage{}
+			continue
+		}
+		key, value, ok := strings.Cut(line, " ")
+		if !ok || value == "" {
+			continue
+		}
+		switch key {
+		case "cpu_usec":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				usage.CPUTimeMs = n / 1000
+			}
+		case "cpu_nsec":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				usage.CPUTimeMs = n / int64(time.Millisecond)
+			}
+		case "memory_peak":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				usage.PeakMemoryKB = n / 1024
+			}
+		}
+	}
+
+	return &usage
+}
@@ -0,0 +1,48 @@
+package code_executor
+
+import (
+	"fmt"
+
+	"go-code-runner/internal/models"
+)
+
+// runVerdict classifies a finished run by how the sandbox ended. It does not
+// look at the output, so a clean exit is reported as Accepted and may still
+// turn into WrongAnswer once the output is compared.
+func runVerdict(result *ExecutionResult) models.Verdict {
+	switch {
+	case result.Truncated:
+		return models.VerdictOutputLimitExceeded
+	case result.TimedOut:
+		return models.VerdictTimeLimitExceeded
+	case result.OOMKilled:
+		return models.VerdictMemoryLimitExceeded
+	case result.ExitCode != 0:
+		return models.VerdictRuntimeError
+	}
+	return models.VerdictAccepted
+}
+
+// verdictMessage explains verdicts that leave nothing useful on stderr.
+func verdictMessage(verdict models.Verdict, limits Limits) string {
+	switch verdict {
+	case models.VerdictTimeLimitExceeded:
+		return fmt.Sprintf("time limit exceeded (%v)", limits.TimeLimit)
+	case models.VerdictMemoryLimitExceeded:
+		return fmt.Sprintf("memory limit exceeded (%d MB)", limits.MemoryMB)
+	case models.VerdictOutputLimitExceeded:
+		return "output limit exceeded"
+	}
+	return ""
+}
+
+// overallVerdict is the verdict of the first failing test case, or Accepted
+// when every test case passed.
+func overallVerdict(testResults []models.TestResult) models.Verdict {
+	for _, testResult := range testResults {
+		if testResult.Verdict != models.VerdictAccepted {
+			return testResult.Verdict
+		}
+	}
+	return models.VerdictAccepted
+}
@@ -0,0 +1,235 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWarmPoolMaxIdle = 5 * time.Minute
+	// warmPoolCheckInterval is how often the pool looks for expired
+	// containers when nothing else wakes it up.
+	warmPoolCheckInterval = 10 * time.Second
+)
+
+// warmPool keeps started containers ready for every language that has been
+// prepared, so a run only pays for an exec instead of a container start.
+// Containers are handed out once and never return to the pool.
+type warmPool struct {
+	d       *dockerSandbox
+	size    int
+	maxIdle time.Duration
+	wake    chan struct{}
+
+	mu    sync.Mutex
+	kinds map[string]warmKind
+	idle  map[string][]*sandboxContainer
+}
+
+// warmKind is a language together with the access its containers have to
+// the language's caches.
+type warmKind struct {
+	lang           *Language
+	writableCaches bool
+}
+
+func newWarmPool(d *dockerSandbox, size int, maxIdle time.Duration) *warmPool {
+	if maxIdle <= 0 {
+		maxIdle = defaultWarmPoolMaxIdle
+	}
+	p := &warmPool{
+		d:       d,
+		size:    size,
+		maxIdle: maxIdle,
+		wake:    make(chan struct{}, 1),
+		kinds:   make(map[string]warmKind),
+		idle:    make(map[string][]*sandboxContainer),
+	}
+	go p.run()
+	return p
+}
+
+// poolKey identifies containers that are interchangeable for a language:
+// same image, runtime, environment and caches, mounted the same way.
+func poolKey(lang *Language, writableCaches bool) string {
+	parts := append([]string{lang.Image, lang.Runtime}, lang.Env...)
+	for _, cache := range lang.Caches {
+		parts = append(parts, cache.Name+":"+cache.Target)
+	}
+	if writableCaches && len(lang.Caches) > 0 {
+		parts = append(parts, "rw")
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// register keeps containers of lang ready from now on, for languages with
+// caches both for toolchain steps and for the candidate's program.
+func (p *warmPool) register(lang *Language) {
+	kinds := []warmKind{{lang: lang}}
+	if len(lang.Caches) > 0 {
+		kinds = append(kinds, warmKind{lang: lang, writableCaches: true})
+	}
+
+	added := false
+	p.mu.Lock()
+	for _, kind := range kinds {
+		key := poolKey(kind.lang, kind.writableCaches)
+		if _, known := p.kinds[key]; !known {
+			p.kinds[key] = kind
+			added = true
+		}
+	}
+	p.mu.Unlock()
+	if added {
+		p.signal()
+	}
+}
+
+// take hands out the oldest idle container of lang, or nil if none is
+// ready, and triggers a refill.
+func (p *warmPool) take(lang *Language, writableCaches bool) *sandboxContainer {
+	key := poolKey(lang, writableCaches)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.signal()
+
+	idle := p.idle[key]
+	if len(idle) == 0 {
+		return nil
+	}
+	c := idle[0]
+	p.idle[key] = idle[1:]
+	return c
+}
+
+func (p *warmPool) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *warmPool) run() {
+	p.d.removeStale(context.Background())
+
+	ticker := time.NewTicker(warmPoolCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.wake:
+		case <-ticker.C:
+		}
+		p.fill()
+	}
+}
+
+// fill replaces expired containers and starts new ones until every
+// language has size idle containers.
+func (p *warmPool) fill() {
+	p.mu.Lock()
+	kinds := make(map[string]warmKind, len(p.kinds))
+	var expired []*sandboxContainer
+	for key, kind := range p.kinds {
+		kinds[key] = kind
+		fresh := p.idle[key][:0]
+		for _, c := range p.idle[key] {
+			if time.Since(c.created) > p.maxIdle {
+				expired = append(expired, c)
+			} else {
+				fresh = append(fresh, c)
+			}
+		}
+		p.idle[key] = fresh
+	}
+	p.mu.Unlock()
+
+	for _, c := range expired {
+		p.d.destroy("", c)
+	}
+
+	for key, kind := range kinds {
+		p.mu.Lock()
+		missing := p.size - len(p.idle[key])
+		p.mu.Unlock()
+
+		for i := 0; i < missing; i++ {
+			c, err := p.d.startContainer(context.Background(), kind.lang, kind.lang.Limits, "", kind.writableCaches)
+			if err != nil {
+				p.d.logger.Printf("Failed to start warm container for %s: %v", kind.lang.Image, err)
+				break
+			}
+			p.mu.Lock()
+			p.idle[key] = append(p.idle[key], c)
+			p.mu.Unlock()
+		}
+	}
+}
+
+// linkTree makes every file below src that is missing in dst available
+// there, hard-linked where possible so large build outputs are not copied.
+// Parallel runs of a submission link back into the same workspace, so a file
+// that appears in dst meanwhile counts as present: the first run wins.
+func linkTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if _, err := os.Lstat(target); err == nil {
+			return nil
+		}
+		err = os.Link(p, target)
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			err = copyFile(p, target)
+		}
+		if errors.Is(err, fs.ErrExist) {
+			return nil
+		}
+		return err
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
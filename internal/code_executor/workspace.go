@@ -0,0 +1,142 @@
+package code_executor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// workspace is the per-submission directory every sandbox run of that
+// submission works in; the Docker sandbox mounts it as /app.
+type workspace struct {
+	runID string
+	dir   string
+}
+
+// newWorkspace creates the run directory and writes files followed by code,
+// which is stored as the language's source file.
+func (s *service) newWorkspace(code string, lang *Language, files map[string]string) (*workspace, error) {
+	runID := uuid.New().String()
+	s.logger.Printf("[%s] Creating temp directory...", runID)
+	dirStart := time.Now()
+
+	if err := os.MkdirAll(apiContainerBaseDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create base temp dir: %w", err)
+	}
+
+	dir := filepath.Join(apiContainerBaseDir, "runbox-"+runID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	s.logger.Printf("[%s] Temp directory created at %s. (took %v)", runID, dir, time.Since(dirStart))
+
+	ws := &workspace{
+		runID: runID,
+		dir:   dir,
+	}
+
+	s.logger.Printf("[%s] Writing code to file...", runID)
+	writeStart := time.Now()
+
+	if err := ws.writeFiles(files); err != nil {
+		ws.remove()
+		return nil, err
+	}
+
+	codePath := filepath.Join(dir, lang.SourceFile)
+	if err := os.WriteFile(codePath, []byte(code), 0644); err != nil {
+		ws.remove()
+		return nil, fmt.Errorf("failed to write code to file: %w", err)
+	}
+	s.logger.Printf("[%s] Code written to %s. (took %v)", runID, codePath, time.Since(writeStart))
+
+	return ws, nil
+}
+
+// writeInput stores the stdin of a single run and returns its path. Inputs
+// are kept next to the workspace, not in it, in a directory only the API user
+// can enter, so that a run cannot read the hidden inputs of other test cases.
+func (ws *workspace) writeInput(name string, input string) (string, error) {
+	if err := os.MkdirAll(ws.inputDir(), 0700); err != nil {
+		return "", fmt.Errorf("failed to create input dir: %w", err)
+	}
+	path := filepath.Join(ws.inputDir(), name+".txt")
+	if err := os.WriteFile(path, []byte(input), 0600); err != nil {
+		return "", fmt.Errorf("failed to write input to file: %w", err)
+	}
+	return path, nil
+}
+
+func (ws *workspace) inputDir() string {
+	return ws.dir + "-input"
+}
+
+// writeFile stores a per-run file named "<kind>-<name>.txt" and returns its
+// name relative to the workspace.
+func (ws *workspace) writeFile(kind string, name string, content string) (string, error) {
+	fileName := kind + "-" + name + ".txt"
+	if err := os.WriteFile(filepath.Join(ws.dir, fileName), []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s to file: %w", kind, err)
+	}
+	return fileName, nil
+}
+
+// setWorkspaceAccess opens the directories of dir up to the sandbox user,
+// which owns none of them, or closes them again for commands that must not
+// write. Directories the sandbox user created itself are its own and left
+// alone.
+func setWorkspaceAccess(dir string, writable bool) error {
+	mode := fs.FileMode(0755)
+	if writable {
+		mode = 0777
+	}
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return err
+		}
+		if err := os.Chmod(p, mode); err != nil && !errors.Is(err, fs.ErrPermission) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set workspace permissions: %w", err)
+	}
+	return nil
+}
+
+// reclaimWorkspace hands everything in dir back to the API user and takes
+// away the write access of everyone else.
+func reclaimWorkspace(dir string) error {
+	uid, gid := os.Getuid(), os.Getgid()
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := os.Lchown(p, uid, gid); err != nil {
+			return err
+		}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return os.Chmod(p, info.Mode().Perm()&^0022)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reclaim workspace: %w", err)
+	}
+	return nil
+}
+
+func (ws *workspace) remove() {
+	os.RemoveAll(ws.dir)
+	os.RemoveAll(ws.inputDir())
+}
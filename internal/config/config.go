@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	yaml "gopkg.in/yaml.v3"
@@ -13,6 +14,30 @@ import (
 type rawConfig struct {
 	ServerPort               string `yaml:"server_port"`
 	ExecutionTimeoutSeconds  int    `yaml:"execution_timeout_seconds"`
+	MaxConcurrentSandboxes   int    `yaml:"max_concurrent_sandboxes"`
+	MaxParallelTestCases     int    `yaml:"max_parallel_test_cases"`
+	MaxSubmissionBytes       int    `yaml:"max_submission_bytes"`
+	MaxOutputBytes           int    `yaml:"max_output_bytes"` // per stream and run
+	ResultCache              string `yaml:"result_cache"` // memory, postgres or off
+	ResultCacheTTLSeconds    int    `yaml:"result_cache_ttl_seconds"`
+	ResultCacheMaxEntries    int    `yaml:"result_cache_max_entries"`
+	AllowedGoModules         []string `yaml:"allowed_go_modules"` // "path@version" entries
+	GoModuleSource           string `yaml:"go_module_source"`     // GOPROXY layout dir or archive
+	GoToolchains             map[string]string `yaml:"go_toolchains"` // version -> image
+	DefaultGoVersion         string `yaml:"default_go_version"`
+	GoAnalyzers              map[string]string `yaml:"go_analyzers"` // name -> command, after gofmt and vet
+	Sandbox                  string `yaml:"sandbox"` // docker or local
+	DockerHost               string `yaml:"docker_host"`
+	WarmPoolSize             int    `yaml:"warm_pool_size"` // idle containers per image, 0 disables
+	WarmPoolMaxIdleSeconds   int    `yaml:"warm_pool_max_idle_seconds"`
+	SandboxPidsLimit         int    `yaml:"sandbox_pids_limit"` // processes and threads per container
+	SandboxRuntime           string `yaml:"sandbox_runtime"`    // OCI runtime, e.g. runsc
+	SandboxRuntimes          map[string]string `yaml:"sandbox_runtimes"` // language -> runtime
+	TenantRuntimes           map[int]string    `yaml:"tenant_runtimes"`  // company id -> runtime
+	LocalSandboxUID          int    `yaml:"local_sandbox_uid"`
+	LocalSandboxGID          int    `yaml:"local_sandbox_gid"`
+	LocalSandboxMaxProcesses int    `yaml:"local_sandbox_max_processes"`
+	LocalSandboxMaxFileSizeMB int   `yaml:"local_sandbox_max_file_size_mb"`
 	Postgres                 struct {
 		Host     string `yaml:"host"`
 		Port     int    `yaml:"port"`
@@ -24,9 +49,33 @@ type rawConfig struct {
 }
 
 type Config struct {
-	ServerPort       string
-	DBConnStr        string
-	ExecutionTimeout time.Duration
+	ServerPort             string
+	DBConnStr              string
+	ExecutionTimeout       time.Duration
+	MaxConcurrentSandboxes int
+	MaxParallelTestCases   int
+	MaxSubmissionBytes     int
+	MaxOutputBytes         int
+	ResultCache            string
+	ResultCacheTTL         time.Duration
+	ResultCacheMaxEntries  int
+	AllowedGoModules       []string
+	GoModuleSource         string
+	GoToolchains           map[string]string
+	DefaultGoVersion       string
+	GoAnalyzers            map[string]string
+	Sandbox                string
+	DockerHost             string
+	WarmPoolSize           int
+	WarmPoolMaxIdle        time.Duration
+	SandboxPidsLimit       int
+	SandboxRuntime         string
+	SandboxRuntimes        map[string]string
+	TenantRuntimes         map[int]string
+	LocalSandboxUID        int
+	LocalSandboxGID        int
+	LocalSandboxMaxProcesses  int
+	LocalSandboxMaxFileSizeMB int
 }
 
 func Load() (*Config, error) {
@@ -55,6 +104,130 @@ func Load() (*Config, error) {
 			raw.ExecutionTimeoutSeconds = n
 		}
 	}
+	if v := os.Getenv("MAX_CONCURRENT_SANDBOXES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.MaxConcurrentSandboxes = n
+		}
+	}
+	if v := os.Getenv("MAX_PARALLEL_TEST_CASES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.MaxParallelTestCases = n
+		}
+	}
+	if v := os.Getenv("MAX_SUBMISSION_BYTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.MaxSubmissionBytes = n
+		}
+	}
+	if v := os.Getenv("MAX_OUTPUT_BYTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.MaxOutputBytes = n
+		}
+	}
+	if v := os.Getenv("RESULT_CACHE"); v != "" {
+		raw.ResultCache = v
+	}
+	if v := os.Getenv("RESULT_CACHE_TTL_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.ResultCacheTTLSeconds = n
+		}
+	}
+	if v := os.Getenv("RESULT_CACHE_MAX_ENTRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.ResultCacheMaxEntries = n
+		}
+	}
+	if v := os.Getenv("ALLOWED_GO_MODULES"); v != "" {
+		raw.AllowedGoModules = strings.Split(v, ",")
+	}
+	if v := os.Getenv("GO_MODULE_SOURCE"); v != "" {
+		raw.GoModuleSource = v
+	}
+	if v := os.Getenv("GO_TOOLCHAINS"); v != "" {
+		// e.g. "1.21=golang:1.21-alpine,1.22=golang:1.22-alpine"
+		raw.GoToolchains = make(map[string]string)
+		for _, entry := range strings.Split(v, ",") {
+			if version, image, ok := strings.Cut(entry, "="); ok {
+				raw.GoToolchains[strings.TrimSpace(version)] = strings.TrimSpace(image)
+			}
+		}
+	}
+	if v := os.Getenv("DEFAULT_GO_VERSION"); v != "" {
+		raw.DefaultGoVersion = v
+	}
+	if v := os.Getenv("GO_ANALYZERS"); v != "" {
+		// e.g. "staticcheck=staticcheck ./..."
+		raw.GoAnalyzers = make(map[string]string)
+		for _, entry := range strings.Split(v, ",") {
+			if name, cmd, ok := strings.Cut(entry, "="); ok {
+				raw.GoAnalyzers[strings.TrimSpace(name)] = strings.TrimSpace(cmd)
+			}
+		}
+	}
+	if v := os.Getenv("SANDBOX"); v != "" {
+		raw.Sandbox = v
+	}
+	if v := os.Getenv("DOCKER_HOST"); v != "" {
+		raw.DockerHost = v
+	}
+	if v := os.Getenv("WARM_POOL_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.WarmPoolSize = n
+		}
+	}
+	if v := os.Getenv("WARM_POOL_MAX_IDLE_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.WarmPoolMaxIdleSeconds = n
+		}
+	}
+	if v := os.Getenv("SANDBOX_PIDS_LIMIT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.SandboxPidsLimit = n
+		}
+	}
+	if v := os.Getenv("SANDBOX_RUNTIME"); v != "" {
+		raw.SandboxRuntime = v
+	}
+	if v := os.Getenv("SANDBOX_RUNTIMES"); v != "" {
+		// e.g. "go=runsc,python=runsc"
+		raw.SandboxRuntimes = make(map[string]string)
+		for _, entry := range strings.Split(v, ",") {
+			if language, runtime, ok := strings.Cut(entry, "="); ok {
+				raw.SandboxRuntimes[strings.TrimSpace(language)] = strings.TrimSpace(runtime)
+			}
+		}
+	}
+	if v := os.Getenv("TENANT_RUNTIMES"); v != "" {
+		// e.g. "42=runsc"
+		raw.TenantRuntimes = make(map[int]string)
+		for _, entry := range strings.Split(v, ",") {
+			if id, runtime, ok := strings.Cut(entry, "="); ok {
+				if n, err := strconv.Atoi(strings.TrimSpace(id)); err == nil {
+					raw.TenantRuntimes[n] = strings.TrimSpace(runtime)
+				}
+			}
+		}
+	}
+	if v := os.Getenv("LOCAL_SANDBOX_UID"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.LocalSandboxUID = n
+		}
+	}
+	if v := os.Getenv("LOCAL_SANDBOX_GID"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.LocalSandboxGID = n
+		}
+	}
+	if v := os.Getenv("LOCAL_SANDBOX_MAX_PROCESSES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.LocalSandboxMaxProcesses = n
+		}
+	}
+	if v := os.Getenv("LOCAL_SANDBOX_MAX_FILE_SIZE_MB"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			raw.LocalSandboxMaxFileSizeMB = n
+		}
+	}
 
 	if v := os.Getenv("POSTGRES_HOST"); v != "" {
 		raw.Postgres.Host = v
@@ -87,9 +260,64 @@ func Load() (*Config, error) {
 		raw.Postgres.SSLMode,
 	)
 
+	if raw.MaxConcurrentSandboxes <= 0 {
+		raw.MaxConcurrentSandboxes = 4
+	}
+	if raw.MaxParallelTestCases <= 0 {
+		raw.MaxParallelTestCases = 4
+	}
+	if raw.MaxSubmissionBytes <= 0 {
+		raw.MaxSubmissionBytes = 1 << 20
+	}
+	if raw.MaxOutputBytes <= 0 {
+		raw.MaxOutputBytes = 1 << 20
+	}
+	if raw.ResultCache == "" {
+		raw.ResultCache = "memory"
+	}
+	if raw.ResultCacheTTLSeconds <= 0 {
+		raw.ResultCacheTTLSeconds = 600
+	}
+	if raw.ResultCacheMaxEntries <= 0 {
+		raw.ResultCacheMaxEntries = 1000
+	}
+	if raw.WarmPoolMaxIdleSeconds <= 0 {
+		raw.WarmPoolMaxIdleSeconds = 300
+	}
+	if raw.SandboxPidsLimit <= 0 {
+		raw.SandboxPidsLimit = 256
+	}
+	if raw.Sandbox == "" {
+		raw.Sandbox = "docker"
+	}
+
 	return &Config{
-		ServerPort:       raw.ServerPort,
-		DBConnStr:        connStr,
-		ExecutionTimeout: time.Duration(raw.ExecutionTimeoutSeconds) * time.Second,
+		ServerPort:             raw.ServerPort,
+		DBConnStr:              connStr,
+		ExecutionTimeout:       time.Duration(raw.ExecutionTimeoutSeconds) * time.Second,
+		MaxConcurrentSandboxes: raw.MaxConcurrentSandboxes,
+		MaxParallelTestCases:   raw.MaxParallelTestCases,
+		MaxSubmissionBytes:     raw.MaxSubmissionBytes,
+		MaxOutputBytes:         raw.MaxOutputBytes,
+		ResultCache:            raw.ResultCache,
+		ResultCacheTTL:         time.Duration(raw.ResultCacheTTLSeconds) * time.Second,
+		ResultCacheMaxEntries:  raw.ResultCacheMaxEntries,
+		AllowedGoModules:       raw.AllowedGoModules,
+		GoModuleSource:         raw.GoModuleSource,
+		GoToolchains:           raw.GoToolchains,
+		DefaultGoVersion:       raw.DefaultGoVersion,
+		GoAnalyzers:            raw.GoAnalyzers,
+		Sandbox:                raw.Sandbox,
+		DockerHost:             raw.DockerHost,
+		WarmPoolSize:           raw.WarmPoolSize,
+		WarmPoolMaxIdle:        time.Duration(raw.WarmPoolMaxIdleSeconds) * time.Second,
+		SandboxPidsLimit:       raw.SandboxPidsLimit,
+		SandboxRuntime:         raw.SandboxRuntime,
+		SandboxRuntimes:        raw.SandboxRuntimes,
+		TenantRuntimes:         raw.TenantRuntimes,
+		LocalSandboxUID:        raw.LocalSandboxUID,
+		LocalSandboxGID:        raw.LocalSandboxGID,
+		LocalSandboxMaxProcesses:  raw.LocalSandboxMaxProcesses,
+		LocalSandboxMaxFileSizeMB: raw.LocalSandboxMaxFileSizeMB,
 	}, nil
 }
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyzeRequest struct {
+	Language string            `json:"language" binding:"required"`
+	Code     string            `json:"code"`
+	Files    map[string]string `json:"files,omitempty"`
+	Version  string            `json:"version,omitempty"`
+}
+
+type AnalyzeResponse struct {
+	Success     bool                `json:"success"`
+	Diagnostics []models.Diagnostic `json:"diagnostics"`
+	Error       string              `json:"error,omitempty"`
+}
+
+// MakeAnalyzeHandler creates a handler that runs the language's static
+// analyzers, e.g. gofmt and go vet, over a submission without running it.
+func MakeAnalyzeHandler(executorService code_executor.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AnalyzeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, AnalyzeResponse{Error: "Invalid request payload: " + err.Error()})
+			return
+		}
+		if req.Code == "" && len(req.Files) == 0 {
+			c.JSON(http.StatusBadRequest, AnalyzeResponse{Error: "Invalid request payload: code or files is required"})
+			return
+		}
+
+		opts := append(tenantOptions(c), code_executor.WithFiles(req.Files), code_executor.WithVersion(req.Version))
+		diagnostics, err := executorService.Analyze(c.Request.Context(), req.Code, req.Language, opts...)
+		if err != nil {
+			status := executeErrorStatus(err)
+			if errors.Is(err, code_executor.ErrAnalysisUnsupported) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, AnalyzeResponse{Error: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, AnalyzeResponse{Success: true, Diagnostics: diagnostics})
+	}
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"go-code-runner/internal/code_executor"
 	"go-code-runner/internal/models"
 	"log"
@@ -11,15 +13,29 @@ import (
 
 type ExecuteRequest struct {
 	Language  string `json:"language" binding:"required"`
-	Code      string `json:"code" binding:"required"`
+	Code      string `json:"code"`
 	ProblemID int    `json:"problem_id,omitempty"`
+	// Files is an optional file tree (relative path to content) for
+	// multi-file submissions; Code, if set, replaces the main source file.
+	Files map[string]string `json:"files,omitempty"`
+	// Version picks a toolchain version, e.g. "1.21" for Go.
+	Version string `json:"version,omitempty"`
+	// TestID attributes the run to a candidate's coding test in the
+	// execution history.
+	TestID string `json:"test_id,omitempty" binding:"omitempty,max=36"`
 }
 
 type ExecuteResponse struct {
-	Success     bool                `json:"success"`
-	Output      string              `json:"output,omitempty"`
-	Error       string              `json:"error,omitempty"`
-	TestResults []models.TestResult `json:"test_results,omitempty"`
+	Success      bool                  `json:"success"`
+	Verdict      models.Verdict        `json:"verdict,omitempty"`
+	Output       string                `json:"output,omitempty"`
+	Truncated    bool                  `json:"truncated,omitempty"`
+	Error        string                `json:"error,omitempty"`
+	CompileError string                `json:"compile_error,omitempty"`
+	Diagnostics  []models.Diagnostic   `json:"diagnostics,omitempty"`
+	Usage        *models.ResourceUsage `json:"usage,omitempty"`
+	TestResults  []models.TestResult   `json:"test_results,omitempty"`
+	Cached       bool                  `json:"cached,omitempty"`
 }
 
 func MakeExecuteHandler(executorService code_executor.Service) gin.HandlerFunc {
@@ -34,54 +50,111 @@ func MakeExecuteHandler(executorService code_executor.Service) gin.HandlerFunc {
 			return
 		}
 
-		if req.Language != "go" {
-			c.JSON(http.StatusBadRequest, ExecuteResponse{
-				Success: false,
-				Error:   "Unsupported language. Only 'go' is supported.",
-			})
-			return
-		}
+		status, resp := runExecuteRequest(c.Request.Context(), executorService, req, tenantOptions(c)...)
+		c.JSON(status, resp)
+	}
+}
+
+// tenantOptions runs the request on behalf of the company whose API key it
+// carries, if any, see middleware.OptionalAPIKeyAuth.
+func tenantOptions(c *gin.Context) []code_executor.Option {
+	companyID, exists := c.Get("company_id")
+	if !exists {
+		return nil
+	}
+	return []code_executor.Option{code_executor.WithTenant(companyID.(int))}
+}
 
-		if req.ProblemID > 0 {
-			log.Printf("Executing code for problem ID: %d", req.ProblemID)
+// errMissingCode rejects execute requests with nothing to run.
+const errMissingCode = "Invalid request payload: code or files is required"
 
-			results, err := executorService.ExecuteForProblem(c.Request.Context(), req.Code, req.Language, req.ProblemID)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, ExecuteResponse{
-					Success: false,
-					Error:   err.Error(),
-				})
-				return
-			}
+// requestOptions turns the optional fields of req into executor options.
+func requestOptions(req ExecuteRequest) []code_executor.Option {
+	return []code_executor.Option{
+		code_executor.WithFiles(req.Files),
+		code_executor.WithVersion(req.Version),
+		code_executor.WithCodingTest(req.TestID),
+	}
+}
 
-			c.JSON(http.StatusOK, ExecuteResponse{
-				Success:     results.Success,
-				TestResults: results.TestResults,
-			})
-			return
+// runExecuteRequest executes req and builds the HTTP status and payload that
+// both the plain and the streaming execute endpoints return.
+func runExecuteRequest(ctx context.Context, executorService code_executor.Service, req ExecuteRequest, opts ...code_executor.Option) (int, ExecuteResponse) {
+	if req.Code == "" && len(req.Files) == 0 {
+		return http.StatusBadRequest, ExecuteResponse{
+			Success: false,
+			Error:   errMissingCode,
 		}
+	}
+	opts = append(opts, requestOptions(req)...)
 
-		result, err := executorService.Execute(c.Request.Context(), req.Code, req.Language)
+	if req.ProblemID > 0 {
+		log.Printf("Executing code for problem ID: %d", req.ProblemID)
+
+		results, err := executorService.ExecuteForProblem(ctx, req.Code, req.Language, req.ProblemID, opts...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ExecuteResponse{
+			return executeErrorStatus(err), ExecuteResponse{
 				Success: false,
 				Error:   err.Error(),
-			})
-			return
+			}
 		}
 
-		if result.Error != "" {
-			c.JSON(http.StatusOK, ExecuteResponse{
-				Success: false,
-				Output:  result.Output,
-				Error:   result.Error,
-			})
-			return
+		return http.StatusOK, ExecuteResponse{
+			Success:      results.Success,
+			Verdict:      results.Verdict,
+			CompileError: results.CompileError,
+			Diagnostics:  results.Diagnostics,
+			TestResults:  results.TestResults,
+			Cached:       results.Cached,
+		}
+	}
+
+	result, err := executorService.Execute(ctx, req.Code, req.Language, opts...)
+	if err != nil {
+		return executeErrorStatus(err), ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	if result.CompileError != "" {
+		return http.StatusOK, ExecuteResponse{
+			Success:      false,
+			Verdict:      result.Verdict,
+			CompileError: result.CompileError,
+			Diagnostics:  result.Diagnostics,
+		}
+	}
+
+	if result.Error != "" {
+		return http.StatusOK, ExecuteResponse{
+			Success:     false,
+			Verdict:     result.Verdict,
+			Output:      result.Output,
+			Truncated:   result.Truncated,
+			Error:       result.Error,
+			Diagnostics: result.Diagnostics,
+			Usage:       result.Usage,
 		}
+	}
+
+	return http.StatusOK, ExecuteResponse{
+		Success:   result.Verdict == models.VerdictAccepted,
+		Verdict:   result.Verdict,
+		Output:    result.Output,
+		Truncated: result.Truncated,
+		Usage:     result.Usage,
+	}
+}
 
-		c.JSON(http.StatusOK, ExecuteResponse{
-			Success: true,
-			Output:  result.Output,
-		})
+// executeErrorStatus maps executor errors caused by the request to 400 and
+// everything else to 500.
+func executeErrorStatus(err error) int {
+	if errors.Is(err, code_executor.ErrUnsupportedLanguage) ||
+		errors.Is(err, code_executor.ErrUnsupportedVersion) ||
+		errors.Is(err, code_executor.ErrInvalidFiles) ||
+		errors.Is(err, code_executor.ErrInvalidCodingTest) {
+		return http.StatusBadRequest
 	}
+	return http.StatusInternalServerError
 }
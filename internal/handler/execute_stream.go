@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type streamMessage struct {
+	event string
+	data  any
+}
+
+type testCaseEvent struct {
+	Index      int                `json:"index"`
+	TestCaseID int                `json:"test_case_id"`
+	Status     string             `json:"status"` // started, passed, failed
+	Result     *models.TestResult `json:"result,omitempty"`
+}
+
+type outputEvent struct {
+	Index      int    `json:"index"`
+	TestCaseID int    `json:"test_case_id"`
+	Stream     string `json:"stream"`
+	Data       string `json:"data"`
+}
+
+// MakeExecuteStreamHandler creates a handler that executes code like
+// MakeExecuteHandler but streams progress as Server-Sent Events:
+//   - "output":    a chunk of stdout/stderr of a running test case
+//   - "test_case": a test case started, passed or failed
+//   - "result":    the final ExecuteResponse, identical to POST /execute
+func MakeExecuteStreamHandler(executorService code_executor.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ExecuteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ExecuteResponse{
+				Success: false,
+				Error:   "Invalid request payload: " + err.Error(),
+			})
+			return
+		}
+
+		ctx := c.Request.Context()
+		messages := make(chan streamMessage, 64)
+
+		send := func(msg streamMessage) {
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+			}
+		}
+
+		onEvent := func(e code_executor.Event) {
+			switch e.Type {
+			case code_executor.EventOutput:
+				send(streamMessage{event: "output", data: outputEvent{
+					Index:      e.Index,
+					TestCaseID: e.TestCaseID,
+					Stream:     e.Stream,
+					Data:       e.Data,
+				}})
+			case code_executor.EventTestCaseStarted:
+				send(streamMessage{event: "test_case", data: testCaseEvent{
+					Index:      e.Index,
+					TestCaseID: e.TestCaseID,
+					Status:     "started",
+				}})
+			case code_executor.EventTestCaseFinished:
+				status := "failed"
+				if e.Result.Passed {
+					status = "passed"
+				}
+				send(streamMessage{event: "test_case", data: testCaseEvent{
+					Index:      e.Index,
+					TestCaseID: e.TestCaseID,
+					Status:     status,
+					Result:     e.Result,
+				}})
+			}
+		}
+
+		// The gin context is recycled once the client goes away, so nothing
+		// may read it from the goroutine.
+		opts := append(tenantOptions(c), code_executor.WithEvents(onEvent))
+		go func() {
+			defer close(messages)
+			_, resp := runExecuteRequest(ctx, executorService, req, opts...)
+			send(streamMessage{event: "result", data: resp})
+		}()
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+
+		c.Stream(func(w io.Writer) bool {
+			msg, ok := <-messages
+			if !ok {
+				return false
+			}
+			c.SSEvent(msg.event, msg.data)
+			return true
+		})
+	}
+}
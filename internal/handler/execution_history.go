@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	svc "go-code-runner/internal/service/executions"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type ExecutionHistoryHandler struct{ svc svc.Service }
+
+func NewExecutionHistoryHandler(s svc.Service) *ExecutionHistoryHandler {
+	return &ExecutionHistoryHandler{svc: s}
+}
+
+// ListCompanyExecutions handles GET /api/v1/companies/executions
+func (h *ExecutionHistoryHandler) ListCompanyExecutions(c *gin.Context) {
+	companyID, exists := c.Get("company_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "unauthorized"})
+		return
+	}
+	limit, offset, err := pagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	executions, total, err := h.svc.ListByCompany(c.Request.Context(), companyID.(int), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "executions": executions, "total": total, "limit": limit, "offset": offset})
+}
+
+// ListTestExecutions handles GET /api/v1/companies/tests/:test_id/executions
+func (h *ExecutionHistoryHandler) ListTestExecutions(c *gin.Context) {
+	companyID, exists := c.Get("company_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "unauthorized"})
+		return
+	}
+	limit, offset, err := pagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	executions, total, err := h.svc.ListByTest(c.Request.Context(), companyID.(int), c.Param("test_id"), limit, offset)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, svc.ErrTestNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "executions": executions, "total": total, "limit": limit, "offset": offset})
+}
+
+// pagination reads the limit (default 20, at most 100) and offset query
+// parameters.
+func pagination(c *gin.Context) (int, int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, errors.New("offset must be a non-negative integer")
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	svc "go-code-runner/internal/service/execution_jobs"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExecutionJobHandler struct{ svc svc.Service }
+
+func NewExecutionJobHandler(s svc.Service) *ExecutionJobHandler { return &ExecutionJobHandler{svc: s} }
+
+// Submit handles POST /api/v1/executions
+func (h *ExecutionJobHandler) Submit(c *gin.Context) {
+	var req ExecuteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	if req.Code == "" && len(req.Files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": errMissingCode})
+		return
+	}
+
+	opts := append(tenantOptions(c), requestOptions(req)...)
+	job, err := h.svc.Submit(c.Request.Context(), req.Code, req.Language, req.ProblemID, opts...)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"success": true, "job": job})
+}
+
+// Get handles GET /api/v1/executions/:id
+func (h *ExecutionJobHandler) Get(c *gin.Context) {
+	job, err := h.svc.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "job": job})
+}
+
+// Cancel handles DELETE /api/v1/executions/:id
+func (h *ExecutionJobHandler) Cancel(c *gin.Context) {
+	job, err := h.svc.Cancel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "job": job})
+}
+
+func jobErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, svc.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, svc.ErrJobFinished):
+		return http.StatusConflict
+	}
+	return executeErrorStatus(err)
+}
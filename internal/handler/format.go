@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FormatRequest struct {
+	Language string `json:"language" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+	Version  string `json:"version,omitempty"`
+}
+
+type FormatResponse struct {
+	Success     bool                `json:"success"`
+	Code        string              `json:"code,omitempty"`
+	Diagnostics []models.Diagnostic `json:"diagnostics,omitempty"`
+	Error       string              `json:"error,omitempty"`
+}
+
+// MakeFormatHandler creates a handler that formats a submission with the
+// language's canonical formatter, e.g. gofmt.
+func MakeFormatHandler(executorService code_executor.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req FormatRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, FormatResponse{Error: "Invalid request payload: " + err.Error()})
+			return
+		}
+
+		opts := append(tenantOptions(c), code_executor.WithVersion(req.Version))
+		result, err := executorService.Format(c.Request.Context(), req.Code, req.Language, opts...)
+		if err != nil {
+			status := executeErrorStatus(err)
+			if errors.Is(err, code_executor.ErrFormatUnsupported) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, FormatResponse{Error: err.Error()})
+			return
+		}
+
+		if len(result.Diagnostics) > 0 {
+			c.JSON(http.StatusOK, FormatResponse{
+				Error:       result.Diagnostics[0].Message,
+				Diagnostics: result.Diagnostics,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, FormatResponse{Success: true, Code: result.Code})
+	}
+}
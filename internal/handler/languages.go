@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"go-code-runner/internal/code_executor"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MakeListLanguagesHandler creates a handler for listing the supported languages
+func MakeListLanguagesHandler(executorService code_executor.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"success":   true,
+			"languages": executorService.Languages(),
+		})
+	}
+}
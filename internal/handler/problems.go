@@ -50,7 +50,7 @@ func MakeGetProblemHandler(problemService problems.Service) gin.HandlerFunc {
 
 		c.JSON(http.StatusOK, gin.H{
 			"success":    true,
-			"problem":    problem,
+			"problem":    publicProblem(problem),
 			"test_cases": visibleTestCases,
 		})
 	}
@@ -68,9 +68,29 @@ func MakeListProblemsHandler(problemService problems.Service) gin.HandlerFunc {
 			return
 		}
 
+		public := make([]*models.Problem, len(problems))
+		for i, problem := range problems {
+			public[i] = publicProblem(problem)
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"success":  true,
-			"problems": problems,
+			"problems": public,
 		})
 	}
 }
+
+// publicProblem returns a copy of problem that is safe to show candidates:
+// the source of a custom checker is left out, only the checker type is kept.
+func publicProblem(problem *models.Problem) *models.Problem {
+	if problem == nil || problem.Checker == nil {
+		return problem
+	}
+	public := *problem
+	public.Checker = &models.Checker{
+		Type:       problem.Checker.Type,
+		AbsEpsilon: problem.Checker.AbsEpsilon,
+		RelEpsilon: problem.Checker.RelEpsilon,
+	}
+	return &public
+}
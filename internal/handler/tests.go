@@ -90,6 +90,7 @@ func (h *CodingTestHandler) SubmitTest(c *gin.Context) {
 
 	var req struct {
 		Code             string `json:"code" binding:"required"`
+		Language         string `json:"language"` // enables code analysis
 		PassedPercentage int    `json:"passed_percentage" binding:"min=0,max=100"`
 	}
 
@@ -98,7 +99,7 @@ func (h *CodingTestHandler) SubmitTest(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.SubmitTest(c.Request.Context(), testID, req.Code, req.PassedPercentage); err != nil {
+	if err := h.service.SubmitTest(c.Request.Context(), testID, req.Code, req.Language, req.PassedPercentage); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
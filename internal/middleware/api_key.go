@@ -37,3 +37,15 @@ func APIKeyAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalAPIKeyAuth identifies the company like APIKeyAuth when the request
+// carries an API key and lets anonymous requests through.
+func OptionalAPIKeyAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("X-API-Key") == "" {
+			c.Next()
+			return
+		}
+		APIKeyAuth()(c)
+	}
+}
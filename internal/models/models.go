@@ -12,6 +12,73 @@ type Problem struct {
 	Difficulty  string    `json:"difficulty" db:"difficulty"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Resource limits for a single test case run; nil falls back to the
+	// language defaults. TimeLimitMs is scaled by the language's multiplier.
+	TimeLimitMs   *int     `json:"time_limit_ms,omitempty" db:"time_limit_ms"`
+	MemoryLimitMB *int     `json:"memory_limit_mb,omitempty" db:"memory_limit_mb"`
+	CPUQuota      *float64 `json:"cpu_quota,omitempty" db:"cpu_quota"`
+
+	// Checker decides whether an output is correct; nil compares exactly.
+	Checker *Checker `json:"checker,omitempty" db:"checker"`
+	// Signature turns the problem into a function problem: candidates only
+	// implement the function and the executor generates main.
+	Signature *FunctionSignature `json:"signature,omitempty" db:"signature"`
+	// TestFile is an author-supplied Go _test.go file that grades the
+	// submission instead of test cases. It is never sent to candidates.
+	TestFile *string `json:"-" db:"test_file"`
+	// Files are scaffolding files (path to content) materialized next to
+	// every submission; submitted files with the same path replace them.
+	Files map[string]string `json:"files,omitempty" db:"files"`
+	// GoVersion pins the Go toolchain used for Go submissions, e.g. "1.21".
+	GoVersion *string `json:"go_version,omitempty" db:"go_version"`
+}
+
+// FunctionSignature describes the function a candidate implements. Types are
+// int, float, string or bool, with any number of "[]" suffixes for arrays.
+// Test case inputs hold one JSON argument per line in parameter order and
+// expected outputs the JSON return value.
+type FunctionSignature struct {
+	Name       string          `json:"name"`
+	Params     []FunctionParam `json:"params"`
+	ReturnType string          `json:"return_type"`
+}
+
+// FunctionParam is a single typed parameter of a FunctionSignature
+type FunctionParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// CheckerType selects how a run's output is compared with the expected output
+type CheckerType string
+
+const (
+	// CheckerExact compares the outputs with surrounding whitespace trimmed.
+	CheckerExact CheckerType = "exact"
+	// CheckerTokens compares whitespace-separated tokens.
+	CheckerTokens CheckerType = "tokens"
+	// CheckerFloat compares tokens, numbers within AbsEpsilon or RelEpsilon.
+	// NaN matches NaN, and infinities match if their signs do.
+	CheckerFloat CheckerType = "float"
+	// CheckerUnordered compares the non-empty lines in any order.
+	CheckerUnordered CheckerType = "unordered"
+	// CheckerJSON compares the outputs as JSON values.
+	CheckerJSON CheckerType = "json"
+	// CheckerCustom runs Code as a checker program in the sandbox.
+	CheckerCustom CheckerType = "custom"
+)
+
+// Checker is the output checker configured on a problem
+type Checker struct {
+	Type       CheckerType `json:"type"`
+	AbsEpsilon float64     `json:"abs_epsilon,omitempty"`
+	RelEpsilon float64     `json:"rel_epsilon,omitempty"`
+	// Language and Code hold the source of a custom checker. The program is
+	// called with the input, expected output and actual output file paths,
+	// exits 0 to accept, 1 to reject and prints an optional message.
+	Language string `json:"language,omitempty"`
+	Code     string `json:"code,omitempty"`
 }
 
 // TestCase represents a test case for a problem
@@ -25,20 +92,127 @@ type TestCase struct {
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Verdict is the judge's classification of a single run
+type Verdict string
+
+const (
+	VerdictAccepted            Verdict = "accepted"
+	VerdictWrongAnswer         Verdict = "wrong_answer"
+	VerdictTimeLimitExceeded   Verdict = "time_limit_exceeded"
+	VerdictMemoryLimitExceeded Verdict = "memory_limit_exceeded"
+	VerdictRuntimeError        Verdict = "runtime_error"
+	VerdictCompilationError    Verdict = "compilation_error"
+	VerdictOutputLimitExceeded Verdict = "output_limit_exceeded"
+	VerdictInternalError       Verdict = "internal_error"
+)
+
+// ResourceUsage is what a single run consumed inside the sandbox
+type ResourceUsage struct {
+	WallTimeMs   int64 `json:"wall_time_ms"`
+	CPUTimeMs    int64 `json:"cpu_time_ms"`
+	PeakMemoryKB int64 `json:"peak_memory_kb"`
+}
+
+// Diagnostic is a compiler message, analyzer finding or runtime crash
+// located in a submission file, for the editor to underline. File is
+// relative to the submission root; Line and Column are 1-based and zero when
+// unknown.
+type Diagnostic struct {
+	File     string       `json:"file,omitempty"`
+	Line     int          `json:"line,omitempty"`
+	Column   int          `json:"column,omitempty"`
+	Severity string       `json:"severity"` // error, warning or info
+	Message  string       `json:"message"`
+	Source   string       `json:"source,omitempty"` // analyzer, e.g. vet
+	Stack    []StackFrame `json:"stack,omitempty"`  // innermost call first
+}
+
+// StackFrame is one call of a panic's stack trace
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
 // TestResult represents the result of running a test case
 type TestResult struct {
-	TestCaseID     int    `json:"test_case_id"`
-	Input          string `json:"input,omitempty"`
-	ExpectedOutput string `json:"expected_output,omitempty"`
-	ActualOutput   string `json:"actual_output"`
-	Error          string `json:"error,omitempty"`
-	Passed         bool   `json:"passed"`
+	TestCaseID     int            `json:"test_case_id"`
+	TestName       string         `json:"test_name,omitempty"`
+	Input          string         `json:"input,omitempty"`
+	ExpectedOutput string         `json:"expected_output,omitempty"`
+	ActualOutput   string         `json:"actual_output"`
+	Truncated      bool           `json:"truncated,omitempty"` // output limit hit
+	Error          string         `json:"error,omitempty"`
+	Passed         bool           `json:"passed"`
+	Verdict        Verdict        `json:"verdict"`
+	CheckerMessage string         `json:"checker_message,omitempty"`
+	Diagnostics    []Diagnostic   `json:"diagnostics,omitempty"`
+	Usage          *ResourceUsage `json:"usage,omitempty"`
 }
 
 // ExecutionResults represents the results of running code against multiple test cases
 type ExecutionResults struct {
-	Success    bool         `json:"success"`
-	TestResults []TestResult `json:"test_results"`
+	Success      bool         `json:"success"`
+	Verdict      Verdict      `json:"verdict"`
+	TestResults  []TestResult `json:"test_results"`
+	CompileError string       `json:"compile_error,omitempty"`
+	Diagnostics  []Diagnostic `json:"diagnostics,omitempty"`
+	Cached       bool         `json:"cached,omitempty"` // served from the result cache
+}
+
+// ExecutionJob represents an asynchronous execution request and its progress
+type ExecutionJob struct {
+	ID           string       `json:"id" db:"id"`
+	Language     string       `json:"language" db:"language"`
+	Code         string       `json:"-" db:"code"`
+	ProblemID    *int         `json:"problem_id,omitempty" db:"problem_id"`
+	Status       string       `json:"status" db:"status"` // queued, running, finished, failed, cancelled
+	Success      bool         `json:"success" db:"success"`
+	Output       string       `json:"output,omitempty" db:"output"`
+	Error        string       `json:"error,omitempty" db:"error"`
+	CompileError string       `json:"compile_error,omitempty" db:"compile_error"`
+	TestResults  []TestResult `json:"test_results" db:"test_results"`
+	StartedAt    *time.Time   `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt   *time.Time   `json:"finished_at,omitempty" db:"finished_at"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusFinished  = "finished"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// Execution is the audit record of one run of a submission through the
+// executor. Only the hash of the submitted code is kept.
+type Execution struct {
+	ID         int64               `json:"id" db:"id"`
+	CodeHash   string              `json:"code_hash" db:"code_hash"` // SHA-256 of the code and files
+	Language   string              `json:"language" db:"language"`
+	Version    string              `json:"version,omitempty" db:"version"`
+	ProblemID  *int                `json:"problem_id,omitempty" db:"problem_id"`
+	CompanyID  *int                `json:"company_id,omitempty" db:"company_id"`
+	TestID     *string             `json:"test_id,omitempty" db:"test_id"`
+	Verdict    Verdict             `json:"verdict,omitempty" db:"verdict"`
+	TestCases  []ExecutionTestCase `json:"test_cases" db:"test_cases"`
+	Cached     bool                `json:"cached" db:"cached"`
+	Error      string              `json:"error,omitempty" db:"error"` // why the execution failed
+	DurationMs int64               `json:"duration_ms" db:"duration_ms"`
+	StartedAt  time.Time           `json:"started_at" db:"started_at"`
+	FinishedAt time.Time           `json:"finished_at" db:"finished_at"`
+	CreatedAt  time.Time           `json:"created_at" db:"created_at"`
+}
+
+// ExecutionTestCase is the verdict and resource usage of one test case of
+// an Execution
+type ExecutionTestCase struct {
+	TestCaseID int            `json:"test_case_id,omitempty"`
+	TestName   string         `json:"test_name,omitempty"`
+	Verdict    Verdict        `json:"verdict"`
+	Usage      *ResourceUsage `json:"usage,omitempty"`
 }
 
 type Company struct {
@@ -65,6 +239,7 @@ type CodingTest struct {
 	TestDurationMinutes  int        `json:"test_duration_minutes" db:"test_duration_minutes"`
 	SubmissionCode       *string    `json:"submission_code" db:"submission_code"`
 	PassedPercentage     *int       `json:"passed_percentage" db:"passed_percentage"`
+	CodeAnalysis         []Diagnostic `json:"code_analysis,omitempty" db:"code_analysis"` // static analysis of the submission
 	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
 }
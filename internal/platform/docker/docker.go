@@ -0,0 +1,404 @@
+// Package docker is a minimal client for the Docker Engine API, covering
+// what the sandbox needs: images and the container lifecycle.
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultHost is the daemon socket mounted into the API container.
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// Client talks to a Docker daemon over its HTTP API.
+type Client struct {
+	http    *http.Client
+	baseURL string
+}
+
+// NewClient returns a client for host, a "unix://" socket path or a
+// "tcp://" address as in DOCKER_HOST. An empty host means DefaultHost.
+func NewClient(host string) *Client {
+	if host == "" {
+		host = DefaultHost
+	}
+
+	if addr, ok := strings.CutPrefix(host, "tcp://"); ok {
+		return &Client{http: &http.Client{}, baseURL: "http://" + addr}
+	}
+
+	socket := strings.TrimPrefix(host, "unix://")
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Client{http: &http.Client{Transport: transport}, baseURL: "http://docker"}
+}
+
+// Error is a non-2xx response of the daemon.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("docker: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// IsNotFound reports whether err is a 404 from the daemon, e.g. for a
+// missing image or container.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// ContainerConfig is the body of a container create request.
+type ContainerConfig struct {
+	Image      string
+	Cmd        []string
+	Env        []string          `json:",omitempty"`
+	WorkingDir string            `json:",omitempty"`
+	Labels     map[string]string `json:",omitempty"`
+	// User is the "uid:gid" the container's processes run as.
+	User       string     `json:",omitempty"`
+	HostConfig HostConfig `json:"HostConfig"`
+}
+
+// HostConfig holds the resource limits, mounts and security options of a
+// container.
+type HostConfig struct {
+	Binds          []string          `json:",omitempty"`
+	NetworkMode    string            `json:",omitempty"`
+	Runtime        string            `json:",omitempty"`
+	ReadonlyRootfs bool              `json:",omitempty"`
+	Tmpfs          map[string]string `json:",omitempty"`
+	CapDrop        []string          `json:",omitempty"`
+	SecurityOpt    []string          `json:",omitempty"`
+	Ulimits        []Ulimit          `json:",omitempty"`
+	Resources
+}
+
+// Ulimit is an rlimit set on the container's processes.
+type Ulimit struct {
+	Name string
+	Soft int64
+	Hard int64
+}
+
+// Resources are the limits that can also be changed on a running
+// container. Memory is in bytes.
+type Resources struct {
+	Memory     int64 `json:",omitempty"`
+	MemorySwap int64 `json:",omitempty"`
+	NanoCPUs   int64 `json:"NanoCpus,omitempty"`
+	PidsLimit  int64 `json:",omitempty"`
+}
+
+// ExecConfig is the body of an exec create request.
+type ExecConfig struct {
+	Cmd          []string
+	WorkingDir   string `json:",omitempty"`
+	AttachStdout bool
+	AttachStderr bool
+}
+
+// ExecState is the state reported by an exec inspect.
+type ExecState struct {
+	Running  bool
+	ExitCode int
+}
+
+// ContainerState is the state reported by a container inspect.
+type ContainerState struct {
+	Status    string
+	Running   bool
+	ExitCode  int
+	OOMKilled bool
+	Error     string
+}
+
+// Ping checks that the daemon is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/_ping", nil, nil, nil)
+}
+
+// Runtimes lists the names of the OCI runtimes the daemon is configured
+// with, e.g. runc and runsc.
+func (c *Client) Runtimes(ctx context.Context) ([]string, error) {
+	var info struct {
+		Runtimes map[string]json.RawMessage
+	}
+	if err := c.do(ctx, http.MethodGet, "/info", nil, nil, &info); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(info.Runtimes))
+	for name := range info.Runtimes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ImageExists reports whether image is available locally.
+func (c *Client) ImageExists(ctx context.Context, image string) (bool, error) {
+	err := c.do(ctx, http.MethodGet, "/images/"+image+"/json", nil, nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PullImage pulls image and waits for the pull to finish.
+func (c *Client) PullImage(ctx context.Context, image string) error {
+	resp, err := c.request(ctx, http.MethodPost, "/images/create", url.Values{"fromImage": {image}}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Failures after the pull started arrive as messages in the progress
+	// stream rather than as a status code.
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("docker: reading pull progress: %w", err)
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("docker: pulling %s: %s", image, msg.Error)
+		}
+	}
+}
+
+// CreateContainer creates a stopped container and returns its ID.
+func (c *Client) CreateContainer(ctx context.Context, name string, cfg *ContainerConfig) (string, error) {
+	var query url.Values
+	if name != "" {
+		query = url.Values{"name": {name}}
+	}
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/containers/create", query, cfg, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (c *Client) StartContainer(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/containers/"+id+"/start", nil, nil, nil)
+}
+
+// WaitContainer blocks until the container stops and returns its exit code.
+func (c *Client) WaitContainer(ctx context.Context, id string) (int, error) {
+	var waited struct {
+		StatusCode int
+		Error      *struct{ Message string }
+	}
+	if err := c.do(ctx, http.MethodPost, "/containers/"+id+"/wait", nil, nil, &waited); err != nil {
+		return 0, err
+	}
+	if waited.Error != nil && waited.Error.Message != "" {
+		return 0, fmt.Errorf("docker: waiting for container: %s", waited.Error.Message)
+	}
+	return waited.StatusCode, nil
+}
+
+func (c *Client) InspectContainer(ctx context.Context, id string) (*ContainerState, error) {
+	var inspected struct {
+		State ContainerState
+	}
+	if err := c.do(ctx, http.MethodGet, "/containers/"+id+"/json", nil, nil, &inspected); err != nil {
+		return nil, err
+	}
+	return &inspected.State, nil
+}
+
+// UpdateContainer changes the resource limits of a container.
+func (c *Client) UpdateContainer(ctx context.Context, id string, resources Resources) error {
+	return c.do(ctx, http.MethodPost, "/containers/"+id+"/update", nil, resources, nil)
+}
+
+// ListContainers returns the IDs of all containers, running or not, that
+// carry label.
+func (c *Client) ListContainers(ctx context.Context, label string) ([]string, error) {
+	filters, err := json.Marshal(map[string][]string{"label": {label}})
+	if err != nil {
+		return nil, err
+	}
+	var containers []struct {
+		ID string `json:"Id"`
+	}
+	query := url.Values{"all": {"1"}, "filters": {string(filters)}}
+	if err := c.do(ctx, http.MethodGet, "/containers/json", query, nil, &containers); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(containers))
+	for i, container := range containers {
+		ids[i] = container.ID
+	}
+	return ids, nil
+}
+
+// RemoveContainer force-removes the container, killing it if it still
+// runs. A container that is already gone is not an error.
+func (c *Client) RemoveContainer(ctx context.Context, id string) error {
+	err := c.do(ctx, http.MethodDelete, "/containers/"+id, url.Values{"force": {"1"}}, nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// CreateExec prepares a command to run in a running container and returns
+// the exec ID.
+func (c *Client) CreateExec(ctx context.Context, containerID string, cfg *ExecConfig) (string, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/containers/"+containerID+"/exec", nil, cfg, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// StartExec runs the exec and copies its stdout and stderr to the writers
+// until the command exits or ctx is done. Cancelling ctx stops reading but
+// does not stop the command.
+func (c *Client) StartExec(ctx context.Context, id string, stdout, stderr io.Writer) error {
+	resp, err := c.request(ctx, http.MethodPost, "/exec/"+id+"/start", nil, map[string]bool{"Detach": false, "Tty": false})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return demux(resp.Body, stdout, stderr)
+}
+
+func (c *Client) InspectExec(ctx context.Context, id string) (*ExecState, error) {
+	var state ExecState
+	if err := c.do(ctx, http.MethodGet, "/exec/"+id+"/json", nil, nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// FollowLogs copies the container's stdout and stderr to the writers as
+// they are produced, until the container stops or ctx is done. The
+// container must not use a TTY.
+func (c *Client) FollowLogs(ctx context.Context, id string, stdout, stderr io.Writer) error {
+	query := url.Values{"follow": {"1"}, "stdout": {"1"}, "stderr": {"1"}}
+	resp, err := c.request(ctx, http.MethodGet, "/containers/"+id+"/logs", query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return demux(resp.Body, stdout, stderr)
+}
+
+// demux splits the multiplexed stream of a non-TTY container. Every frame
+// has an 8 byte header: the stream (1 stdout, 2 stderr), three zero bytes
+// and the big-endian payload size.
+func demux(r io.Reader, stdout, stderr io.Writer) error {
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		w := stdout
+		if header[0] == 2 {
+			w = stderr
+		}
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(w, r, size); err != nil {
+			return err
+		}
+	}
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into out unless it is nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	resp, err := c.request(ctx, method, path, query, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("docker: decoding %s response: %w", path, err)
+	}
+	return nil
+}
+
+// request sends a request and turns error statuses into *Error. The caller
+// closes the body of a successful response.
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, in any) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("docker: %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	var msg struct {
+		Message string `json:"message"`
+	}
+	if b, _ := io.ReadAll(resp.Body); json.Unmarshal(b, &msg) == nil && msg.Message != "" {
+		apiErr.Message = msg.Message
+	} else if s := strings.TrimSpace(string(b)); s != "" {
+		apiErr.Message = s
+	}
+	return nil, apiErr
+}
+
+// NanoCPUs converts a fractional CPU count to the API's unit.
+func NanoCPUs(cpus float64) int64 {
+	return int64(cpus * 1e9)
+}
@@ -2,6 +2,7 @@ package coding_test
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go-code-runner/internal/models"
 	"time"
@@ -40,11 +41,12 @@ func (r repository) CreateTest(ctx context.Context, test *models.CodingTest) err
 func (r repository) GetTestByID(ctx context.Context, id string) (*models.CodingTest, error) {
 	query := `
 	SELECT id, company_id, problem_id, candidate_name, candidate_email, status, started_at, completed_at, expires_at, test_duration_minutes, 
-submission_code, passed_percentage, created_at, updated_at
+submission_code, passed_percentage, code_analysis, created_at, updated_at
 FROM coding_tests
 WHERE id = $1`
 
 	var test models.CodingTest
+	var codeAnalysis []byte
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&test.ID,
 		&test.CompanyID,
@@ -58,6 +60,7 @@ WHERE id = $1`
 		&test.TestDurationMinutes,
 		&test.SubmissionCode,
 		&test.PassedPercentage,
+		&codeAnalysis,
 		&test.CreatedAt,
 		&test.UpdatedAt,
 	)
@@ -66,6 +69,10 @@ WHERE id = $1`
 		return nil, err
 	}
 
+	if err := unmarshalCodeAnalysis(codeAnalysis, &test); err != nil {
+		return nil, err
+	}
+
 	return &test, nil
 }
 
@@ -80,10 +87,16 @@ func (r *repository) Update(ctx context.Context, test *models.CodingTest) error
             completed_at = $6,
             submission_code = $7,
             passed_percentage = $8,
-            updated_at = $9
+            code_analysis = $9,
+            updated_at = $10
         WHERE id = $1`
 
-	_, err := r.db.Exec(ctx, query,
+	codeAnalysis, err := marshalCodeAnalysis(test.CodeAnalysis)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query,
 		test.ID,
 		test.CandidateName,
 		test.CandidateEmail,
@@ -92,6 +105,7 @@ func (r *repository) Update(ctx context.Context, test *models.CodingTest) error
 		test.CompletedAt,
 		test.SubmissionCode,
 		test.PassedPercentage,
+		codeAnalysis,
 		time.Now(),
 	)
 
@@ -120,7 +134,7 @@ func (r *repository) GetByCompanyID(ctx context.Context, companyID int) ([]*mode
         SELECT 
             id, company_id, problem_id, candidate_name, candidate_email,
             status, started_at, completed_at, expires_at, test_duration_minutes,
-            submission_code, passed_percentage, created_at, updated_at
+            submission_code, passed_percentage, code_analysis, created_at, updated_at
         FROM coding_tests
         WHERE company_id = $1
         ORDER BY created_at DESC`
@@ -134,6 +148,7 @@ func (r *repository) GetByCompanyID(ctx context.Context, companyID int) ([]*mode
 	var tests []*models.CodingTest
 	for rows.Next() {
 		var test models.CodingTest
+		var codeAnalysis []byte
 		err := rows.Scan(
 			&test.ID,
 			&test.CompanyID,
@@ -147,14 +162,34 @@ func (r *repository) GetByCompanyID(ctx context.Context, companyID int) ([]*mode
 			&test.TestDurationMinutes,
 			&test.SubmissionCode,
 			&test.PassedPercentage,
+			&codeAnalysis,
 			&test.CreatedAt,
 			&test.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if err := unmarshalCodeAnalysis(codeAnalysis, &test); err != nil {
+			return nil, err
+		}
 		tests = append(tests, &test)
 	}
 
 	return tests, nil
 }
+
+// marshalCodeAnalysis stores nil findings, a submission that was not
+// analyzed, as NULL and no findings as an empty array.
+func marshalCodeAnalysis(diagnostics []models.Diagnostic) ([]byte, error) {
+	if diagnostics == nil {
+		return nil, nil
+	}
+	return json.Marshal(diagnostics)
+}
+
+func unmarshalCodeAnalysis(data []byte, test *models.CodingTest) error {
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &test.CodeAnalysis)
+}
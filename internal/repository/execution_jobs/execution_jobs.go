@@ -0,0 +1,167 @@
+package execution_jobs
+
+import (
+	"context"
+	"encoding/json"
+	"go-code-runner/internal/models"
+	"time"
+)
+
+// CreateJob stores a newly submitted job
+func (r *executionJobRepository) CreateJob(ctx context.Context, job *models.ExecutionJob) error {
+	testResults, err := marshalTestResults(job.TestResults)
+	if err != nil {
+		return err
+	}
+
+	q := `
+		INSERT INTO execution_jobs
+		    (id, language, code, problem_id, status, success, output, error, compile_error,
+		     test_results, started_at, finished_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err = r.db.Exec(
+		ctx,
+		q,
+		job.ID,
+		job.Language,
+		job.Code,
+		job.ProblemID,
+		job.Status,
+		job.Success,
+		job.Output,
+		job.Error,
+		job.CompileError,
+		testResults,
+		job.StartedAt,
+		job.FinishedAt,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+
+	return err
+}
+
+// GetJobByID retrieves a job with its (possibly partial) results
+func (r *executionJobRepository) GetJobByID(ctx context.Context, id string) (*models.ExecutionJob, error) {
+	query := `
+		SELECT id, language, code, problem_id, status, success, COALESCE(output, ''), COALESCE(error, ''),
+		       COALESCE(compile_error, ''), test_results, started_at, finished_at, created_at, updated_at
+		FROM execution_jobs
+		WHERE id = $1
+	`
+
+	var job models.ExecutionJob
+	var testResults []byte
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&job.ID,
+		&job.Language,
+		&job.Code,
+		&job.ProblemID,
+		&job.Status,
+		&job.Success,
+		&job.Output,
+		&job.Error,
+		&job.CompileError,
+		&testResults,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(testResults, &job.TestResults); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// UpdateJob persists the status and results of a job. Cancelled jobs are
+// final, so late progress updates from a job being torn down are ignored.
+func (r *executionJobRepository) UpdateJob(ctx context.Context, job *models.ExecutionJob) error {
+	testResults, err := marshalTestResults(job.TestResults)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE execution_jobs
+		SET
+		    status = $2,
+		    success = $3,
+		    output = $4,
+		    error = $5,
+		    compile_error = $6,
+		    test_results = $7,
+		    started_at = $8,
+		    finished_at = $9,
+		    updated_at = $10
+		WHERE id = $1 AND status <> 'cancelled'
+	`
+
+	_, err = r.db.Exec(ctx, query,
+		job.ID,
+		job.Status,
+		job.Success,
+		job.Output,
+		job.Error,
+		job.CompileError,
+		testResults,
+		job.StartedAt,
+		job.FinishedAt,
+		time.Now(),
+	)
+
+	return err
+}
+
+// FailUnfinishedJobs marks every queued or running job as failed with
+// message and returns how many there were
+func (r *executionJobRepository) FailUnfinishedJobs(ctx context.Context, message string) (int64, error) {
+	query := `
+		UPDATE execution_jobs
+		SET
+		    status = 'failed',
+		    error = $1,
+		    finished_at = NOW(),
+		    updated_at = NOW()
+		WHERE status IN ('queued', 'running')
+	`
+
+	tag, err := r.db.Exec(ctx, query, message)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func marshalTestResults(testResults []models.TestResult) ([]byte, error) {
+	if testResults == nil {
+		testResults = []models.TestResult{}
+	}
+	return json.Marshal(testResults)
+}
+
+// CancelJob marks a job as cancelled if it is still queued or running and
+// reports whether it was
+func (r *executionJobRepository) CancelJob(ctx context.Context, id string, finishedAt time.Time) (bool, error) {
+	query := `
+		UPDATE execution_jobs
+		SET
+		    status = 'cancelled',
+		    finished_at = $2,
+		    updated_at = NOW()
+		WHERE id = $1 AND status IN ('queued', 'running')
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, finishedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,30 @@
+package execution_jobs
+
+import (
+	"context"
+	"go-code-runner/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExecutionJobRepository defines the interface for asynchronous execution job persistence
+type ExecutionJobRepository interface {
+	CreateJob(ctx context.Context, job *models.ExecutionJob) error
+	GetJobByID(ctx context.Context, id string) (*models.ExecutionJob, error)
+	UpdateJob(ctx context.Context, job *models.ExecutionJob) error
+	FailUnfinishedJobs(ctx context.Context, message string) (int64, error)
+	CancelJob(ctx context.Context, id string, finishedAt time.Time) (bool, error)
+}
+
+// executionJobRepository implements the ExecutionJobRepository interface
+type executionJobRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewExecutionJobRepository creates a new execution job repository
+func NewExecutionJobRepository(db *pgxpool.Pool) ExecutionJobRepository {
+	return &executionJobRepository{
+		db: db,
+	}
+}
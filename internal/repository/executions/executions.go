@@ -0,0 +1,128 @@
+package executions
+
+import (
+	"context"
+	"encoding/json"
+	"go-code-runner/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const executionColumns = `
+	id, code_hash, language, COALESCE(version, ''), problem_id, company_id, test_id, COALESCE(verdict, ''),
+	test_cases, cached, COALESCE(error, ''), duration_ms, started_at, finished_at, created_at`
+
+// CreateExecution stores the audit record of an execution and sets its ID
+func (r *executionRepository) CreateExecution(ctx context.Context, execution *models.Execution) error {
+	testCases := execution.TestCases
+	if testCases == nil {
+		testCases = []models.ExecutionTestCase{}
+	}
+	data, err := json.Marshal(testCases)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO executions
+		    (code_hash, language, version, problem_id, company_id, test_id, verdict,
+		     test_cases, cached, error, duration_ms, started_at, finished_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, ''), $8, $9, NULLIF($10, ''), $11, $12, $13)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRow(
+		ctx,
+		query,
+		execution.CodeHash,
+		execution.Language,
+		execution.Version,
+		execution.ProblemID,
+		execution.CompanyID,
+		execution.TestID,
+		execution.Verdict,
+		data,
+		execution.Cached,
+		execution.Error,
+		execution.DurationMs,
+		execution.StartedAt,
+		execution.FinishedAt,
+	).Scan(&execution.ID, &execution.CreatedAt)
+}
+
+// ListExecutionsByCompany returns the executions run with the company's API
+// key or for one of its coding tests
+func (r *executionRepository) ListExecutionsByCompany(ctx context.Context, companyID int, limit, offset int) ([]*models.Execution, int, error) {
+	where := `company_id = $1 OR test_id IN (SELECT id FROM coding_tests WHERE company_id = $1)`
+	return r.list(ctx, where, companyID, limit, offset)
+}
+
+// ListExecutionsByTest returns the executions run for a coding test
+func (r *executionRepository) ListExecutionsByTest(ctx context.Context, testID string, limit, offset int) ([]*models.Execution, int, error) {
+	return r.list(ctx, `test_id = $1`, testID, limit, offset)
+}
+
+func (r *executionRepository) list(ctx context.Context, where string, arg any, limit, offset int) ([]*models.Execution, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM executions WHERE `+where, arg).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + executionColumns + `
+		FROM executions
+		WHERE ` + where + `
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(ctx, query, arg, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	executions := []*models.Execution{}
+	for rows.Next() {
+		execution, err := scanExecution(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		executions = append(executions, execution)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return executions, total, nil
+}
+
+func scanExecution(row pgx.Row) (*models.Execution, error) {
+	var execution models.Execution
+	var testCases []byte
+	err := row.Scan(
+		&execution.ID,
+		&execution.CodeHash,
+		&execution.Language,
+		&execution.Version,
+		&execution.ProblemID,
+		&execution.CompanyID,
+		&execution.TestID,
+		&execution.Verdict,
+		&testCases,
+		&execution.Cached,
+		&execution.Error,
+		&execution.DurationMs,
+		&execution.StartedAt,
+		&execution.FinishedAt,
+		&execution.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(testCases, &execution.TestCases); err != nil {
+		return nil, err
+	}
+
+	return &execution, nil
+}
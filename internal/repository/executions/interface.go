@@ -0,0 +1,31 @@
+package executions
+
+import (
+	"context"
+	"go-code-runner/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExecutionRepository defines the interface for the execution history
+type ExecutionRepository interface {
+	CreateExecution(ctx context.Context, execution *models.Execution) error
+	// ListExecutionsByCompany returns a page of the company's executions,
+	// including those of its coding tests, newest first, and their total
+	ListExecutionsByCompany(ctx context.Context, companyID int, limit, offset int) ([]*models.Execution, int, error)
+	// ListExecutionsByTest returns a page of a coding test's executions,
+	// newest first, and their total
+	ListExecutionsByTest(ctx context.Context, testID string, limit, offset int) ([]*models.Execution, int, error)
+}
+
+// executionRepository implements the ExecutionRepository interface
+type executionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewExecutionRepository creates a new execution history repository
+func NewExecutionRepository(db *pgxpool.Pool) ExecutionRepository {
+	return &executionRepository{
+		db: db,
+	}
+}
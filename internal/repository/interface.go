@@ -3,7 +3,10 @@ package repository
 import (
 	"go-code-runner/internal/repository/coding_test"
 	"go-code-runner/internal/repository/company"
+	"go-code-runner/internal/repository/execution_jobs"
+	"go-code-runner/internal/repository/executions"
 	"go-code-runner/internal/repository/problems"
+	"go-code-runner/internal/repository/result_cache"
 	"go-code-runner/internal/repository/test_cases"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,6 +18,9 @@ type Repository interface {
 	test_cases.TestCaseRepository
 	company.Repository
 	coding_test.CodingTestRepository
+	execution_jobs.ExecutionJobRepository
+	result_cache.ResultCacheRepository
+	executions.ExecutionRepository
 }
 
 // repository struct implements the Repository interface
@@ -23,14 +29,20 @@ type repository struct {
 	test_cases.TestCaseRepository
 	company.Repository
 	coding_test.CodingTestRepository
+	execution_jobs.ExecutionJobRepository
+	result_cache.ResultCacheRepository
+	executions.ExecutionRepository
 }
 
 // New creates a new repository instance
 func New(db *pgxpool.Pool) Repository {
 	return &repository{
-		ProblemRepository:    problems.NewProblemRepository(db),
-		TestCaseRepository:   test_cases.NewTestCaseRepository(db),
-		Repository:           company.New(db),
-		CodingTestRepository: coding_test.New(db),
+		ProblemRepository:      problems.NewProblemRepository(db),
+		TestCaseRepository:     test_cases.NewTestCaseRepository(db),
+		Repository:             company.New(db),
+		CodingTestRepository:   coding_test.New(db),
+		ExecutionJobRepository: execution_jobs.NewExecutionJobRepository(db),
+		ResultCacheRepository:  result_cache.NewResultCacheRepository(db),
+		ExecutionRepository:    executions.NewExecutionRepository(db),
 	}
 }
@@ -8,7 +8,7 @@ import (
 // GetProblemByID retrieves a problem by its ID
 func (r *problemRepository) GetProblemByID(ctx context.Context, id int) (*models.Problem, error) {
 	query := `
-		SELECT id, title, description, difficulty, created_at, updated_at
+		SELECT id, title, description, difficulty, time_limit_ms, memory_limit_mb, cpu_quota, checker, signature, test_file, files, go_version, created_at, updated_at
 		FROM problems
 		WHERE id = $1
 	`
@@ -19,6 +19,14 @@ func (r *problemRepository) GetProblemByID(ctx context.Context, id int) (*models
 		&problem.Title,
 		&problem.Description,
 		&problem.Difficulty,
+		&problem.TimeLimitMs,
+		&problem.MemoryLimitMB,
+		&problem.CPUQuota,
+		&problem.Checker,
+		&problem.Signature,
+		&problem.TestFile,
+		&problem.Files,
+		&problem.GoVersion,
 		&problem.CreatedAt,
 		&problem.UpdatedAt,
 	)
@@ -33,7 +41,7 @@ func (r *problemRepository) GetProblemByID(ctx context.Context, id int) (*models
 // ListProblems retrieves all problems
 func (r *problemRepository) ListProblems(ctx context.Context) ([]*models.Problem, error) {
 	query := `
-		SELECT id, title, description, difficulty, created_at, updated_at
+		SELECT id, title, description, difficulty, time_limit_ms, memory_limit_mb, cpu_quota, checker, signature, test_file, files, go_version, created_at, updated_at
 		FROM problems
 		ORDER BY id
 	`
@@ -52,6 +60,14 @@ func (r *problemRepository) ListProblems(ctx context.Context) ([]*models.Problem
 			&problem.Title,
 			&problem.Description,
 			&problem.Difficulty,
+			&problem.TimeLimitMs,
+			&problem.MemoryLimitMB,
+			&problem.CPUQuota,
+			&problem.Checker,
+			&problem.Signature,
+			&problem.TestFile,
+			&problem.Files,
+			&problem.GoVersion,
 			&problem.CreatedAt,
 			&problem.UpdatedAt,
 		)
@@ -72,8 +88,8 @@ func (r *problemRepository) ListProblems(ctx context.Context) ([]*models.Problem
 func (r *problemRepository) CreateProblem(ctx context.Context, p models.Problem) (int, error) {
 	q := `
 		INSERT INTO problems
-		(title, description, difficulty, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		(title, description, difficulty, time_limit_ms, memory_limit_mb, cpu_quota, checker, signature, test_file, files, go_version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id;
     `
 	var id int
@@ -83,6 +99,14 @@ func (r *problemRepository) CreateProblem(ctx context.Context, p models.Problem)
 		p.Title,
 		p.Description,
 		p.Difficulty,
+		p.TimeLimitMs,
+		p.MemoryLimitMB,
+		p.CPUQuota,
+		p.Checker,
+		p.Signature,
+		p.TestFile,
+		p.Files,
+		p.GoVersion,
 		p.CreatedAt,
 		p.UpdatedAt,
 	).Scan(&id)
@@ -0,0 +1,29 @@
+package result_cache
+
+import (
+	"context"
+	"go-code-runner/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ResultCacheRepository persists cached execution results, see
+// code_executor.ResultStore
+type ResultCacheRepository interface {
+	GetCachedResult(ctx context.Context, key string) (*models.ExecutionResults, time.Time, error)
+	PutCachedResult(ctx context.Context, key string, results *models.ExecutionResults, expiresAt time.Time) error
+	PruneCachedResults(ctx context.Context, maxEntries int) error
+}
+
+// resultCacheRepository implements the ResultCacheRepository interface
+type resultCacheRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewResultCacheRepository creates a new result cache repository
+func NewResultCacheRepository(db *pgxpool.Pool) ResultCacheRepository {
+	return &resultCacheRepository{
+		db: db,
+	}
+}
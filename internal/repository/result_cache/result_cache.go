@@ -0,0 +1,76 @@
+package result_cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-code-runner/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetCachedResult returns the results stored under key, or nil results if
+// there are none or they have expired
+func (r *resultCacheRepository) GetCachedResult(ctx context.Context, key string) (*models.ExecutionResults, time.Time, error) {
+	query := `
+		SELECT results, expires_at
+		FROM execution_result_cache
+		WHERE key = $1 AND expires_at > NOW()
+	`
+
+	var data []byte
+	var expiresAt time.Time
+	err := r.db.QueryRow(ctx, query, key).Scan(&data, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var results models.ExecutionResults
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return &results, expiresAt, nil
+}
+
+// PutCachedResult stores results under key, replacing an older entry
+func (r *resultCacheRepository) PutCachedResult(ctx context.Context, key string, results *models.ExecutionResults, expiresAt time.Time) error {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO execution_result_cache (key, results, expires_at, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET results = EXCLUDED.results, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at
+	`
+
+	_, err = r.db.Exec(ctx, query, key, data, expiresAt)
+	return err
+}
+
+// PruneCachedResults deletes expired entries and all but the newest
+// maxEntries
+func (r *resultCacheRepository) PruneCachedResults(ctx context.Context, maxEntries int) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM execution_result_cache WHERE expires_at <= NOW()`); err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM execution_result_cache
+		WHERE key IN (
+		    SELECT key FROM execution_result_cache
+		    ORDER BY created_at DESC
+		    OFFSET $1
+		)
+	`
+
+	_, err := r.db.Exec(ctx, query, maxEntries)
+	return err
+}
@@ -2,11 +2,15 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"go-code-runner/internal/repository"
 	"go-code-runner/internal/service/coding_test"
+	"go-code-runner/internal/service/execution_jobs"
+	"go-code-runner/internal/service/executions"
 	"go-code-runner/internal/service/problems"
 	"log"
 	"os"
+	"sort"
 
 	"github.com/joho/godotenv"
 
@@ -15,6 +19,7 @@ import (
 	"go-code-runner/internal/handler"
 	"go-code-runner/internal/middleware"
 	"go-code-runner/internal/platform/database"
+	"go-code-runner/internal/platform/docker"
 	"go-code-runner/internal/service/company"
 )
 
@@ -54,12 +59,155 @@ func Run() {
 	// 3. domain services & repositories
 	// -----------------------------------------------------------------
 	repo := repository.New(dbpool)
-	executorService := code_executor.NewService(cfg.ExecutionTimeout, logger, repo)
+	languages := code_executor.DefaultRegistry()
+	if len(cfg.GoToolchains) > 0 {
+		versions := make([]string, 0, len(cfg.GoToolchains))
+		for version := range cfg.GoToolchains {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+
+		toolchains := make([]code_executor.Toolchain, 0, len(versions))
+		for _, version := range versions {
+			toolchains = append(toolchains, code_executor.Toolchain{Version: version, Image: cfg.GoToolchains[version]})
+		}
+		if err := languages.SetToolchains("go", toolchains, cfg.DefaultGoVersion); err != nil {
+			logger.Fatalf("invalid go_toolchains: %v", err)
+		}
+	}
+	if len(cfg.GoAnalyzers) > 0 {
+		goLang, err := languages.Get("go")
+		if err != nil {
+			logger.Fatalf("go_analyzers set without a go runtime: %v", err)
+		}
+		names := make([]string, 0, len(cfg.GoAnalyzers))
+		for name := range cfg.GoAnalyzers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		analyzers := append([]code_executor.Analyzer(nil), goLang.Analyzers...)
+		for _, name := range names {
+			analyzers = append(analyzers, code_executor.Analyzer{Name: name, Cmd: cfg.GoAnalyzers[name]})
+		}
+		if err := languages.SetAnalyzers("go", analyzers); err != nil {
+			logger.Fatalf("invalid go_analyzers: %v", err)
+		}
+	}
+	// required maps every configured runtime to what requires it.
+	required := make(map[string][]string)
+	for _, lang := range languages.List() {
+		runtime := cfg.SandboxRuntime
+		if r, ok := cfg.SandboxRuntimes[lang.Name]; ok {
+			runtime = r
+		}
+		if runtime == "" {
+			continue
+		}
+		if err := languages.SetRuntime(lang.Name, runtime); err != nil {
+			logger.Fatalf("invalid sandbox_runtimes: %v", err)
+		}
+		required[runtime] = append(required[runtime], "language "+lang.Name)
+	}
+	for name := range cfg.SandboxRuntimes {
+		if _, err := languages.Get(name); err != nil {
+			logger.Fatalf("invalid sandbox_runtimes: %v", err)
+		}
+	}
+	for companyID, runtime := range cfg.TenantRuntimes {
+		if runtime != "" {
+			required[runtime] = append(required[runtime], fmt.Sprintf("company %d", companyID))
+		}
+	}
+	dockerClient := docker.NewClient(cfg.DockerHost)
+	var sandbox code_executor.Sandbox
+	switch cfg.Sandbox {
+	case "docker":
+		if err := dockerClient.Ping(ctx); err != nil {
+			logger.Printf("warning: docker daemon not reachable: %v", err)
+		}
+		if err := code_executor.VerifyRuntimes(ctx, dockerClient, required); err != nil {
+			logger.Fatalf("sandbox runtimes: %v", err)
+		}
+		sandbox = code_executor.NewDockerSandbox(dockerClient, code_executor.DockerSandboxConfig{
+			WarmPoolSize:    cfg.WarmPoolSize,
+			WarmPoolMaxIdle: cfg.WarmPoolMaxIdle,
+			PidsLimit:       int64(cfg.SandboxPidsLimit),
+		}, logger)
+	case "local":
+		if len(required) > 0 {
+			logger.Fatalf("sandbox runtimes require the docker sandbox")
+		}
+		sandbox, err = code_executor.NewLocalSandbox(code_executor.LocalSandboxConfig{
+			UID:           cfg.LocalSandboxUID,
+			GID:           cfg.LocalSandboxGID,
+			MaxProcesses:  cfg.LocalSandboxMaxProcesses,
+			MaxFileSizeMB: cfg.LocalSandboxMaxFileSizeMB,
+		}, logger)
+		if err != nil {
+			logger.Fatalf("failed to set up sandbox: %v", err)
+		}
+	default:
+		logger.Fatalf("unknown sandbox %q, expected docker or local", cfg.Sandbox)
+	}
+	allowedModules, err := code_executor.ParseModules(cfg.AllowedGoModules)
+	if err != nil {
+		logger.Fatalf("invalid allowed_go_modules: %v", err)
+	}
+	if len(allowedModules) > 0 {
+		goLang, err := languages.Get("go")
+		if err != nil {
+			logger.Fatalf("allowed_go_modules set without a go runtime: %v", err)
+		}
+		// Without a source the module cache is expected to be populated already.
+		if cfg.GoModuleSource != "" {
+			if cfg.Sandbox != "docker" {
+				logger.Fatalf("go_module_source requires the docker sandbox")
+			}
+			if err := code_executor.SeedGoModules(ctx, dockerClient, goLang, cfg.GoModuleSource, allowedModules, logger); err != nil {
+				logger.Fatalf("failed to seed Go module cache: %v", err)
+			}
+		}
+	}
+	var resultCache code_executor.ResultCache
+	switch cfg.ResultCache {
+	case "memory", "postgres":
+		cacheCfg := code_executor.ResultCacheConfig{TTL: cfg.ResultCacheTTL, MaxEntries: cfg.ResultCacheMaxEntries}
+		if cfg.ResultCache == "postgres" {
+			cacheCfg.Store = repo
+		}
+		resultCache = code_executor.NewResultCache(cacheCfg, logger)
+	case "off":
+	default:
+		logger.Fatalf("unknown result_cache %q, expected memory, postgres or off", cfg.ResultCache)
+	}
+	executorService := code_executor.NewService(code_executor.Config{
+		ExecutionTimeout:       cfg.ExecutionTimeout,
+		MaxConcurrentSandboxes: cfg.MaxConcurrentSandboxes,
+		MaxParallelTestCases:   cfg.MaxParallelTestCases,
+		MaxSubmissionBytes:     cfg.MaxSubmissionBytes,
+		MaxOutputBytes:         cfg.MaxOutputBytes,
+		AllowedModules:         allowedModules,
+		Sandbox:                sandbox,
+		TenantRuntimes:         cfg.TenantRuntimes,
+		ResultCache:            resultCache,
+		History:                repo,
+		CodingTests:            repo,
+	}, languages, logger, repo, repo)
 	companyService := company.New(repo)
 	companyHandler := handler.NewCompanyHandler(companyService)
 	problemService := problems.New(repo)
-	codingTestService := coding_test.New(repo, repo, repo, "http://localhost:5173")
+	codingTestService := coding_test.New(repo, repo, repo, executorService, "http://localhost:5173")
 	codingTestHandler := handler.NewCodingTestHandler(codingTestService)
+	executionJobService := execution_jobs.New(repo, executorService, logger, cfg.MaxConcurrentSandboxes)
+	if n, err := executionJobService.FailInterrupted(ctx); err != nil {
+		logger.Fatalf("failed to reconcile execution jobs: %v", err)
+	} else if n > 0 {
+		logger.Printf("marked %d execution jobs interrupted by the last shutdown as failed", n)
+	}
+	executionJobHandler := handler.NewExecutionJobHandler(executionJobService)
+	executionHistoryService := executions.New(repo, repo)
+	executionHistoryHandler := handler.NewExecutionHistoryHandler(executionHistoryService)
 
 	// -----------------------------------------------------------------
 	// 4. Initialize middleware
@@ -69,7 +217,7 @@ func Run() {
 	// -----------------------------------------------------------------
 	// 5. HTTP router + handlers
 	// -----------------------------------------------------------------
-	r := NewRouter(dbpool, problemService, executorService, companyHandler, codingTestHandler)
+	r := NewRouter(dbpool, problemService, executorService, companyHandler, codingTestHandler, executionJobHandler, executionHistoryHandler)
 
 	addr := ":" + cfg.ServerPort
 	logger.Printf("starting HTTP server on %s", addr)
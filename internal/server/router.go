@@ -15,6 +15,8 @@ func NewRouter(
 	execSvc code_executor.Service,
 	companyHandler *handler.CompanyHandler,
 	codingTestHandler *handler.CodingTestHandler,
+	executionJobHandler *handler.ExecutionJobHandler,
+	executionHistoryHandler *handler.ExecutionHistoryHandler,
 ) *gin.Engine {
 	r := gin.Default()
 
@@ -22,10 +24,21 @@ func NewRouter(
 
 	v1 := r.Group("/api/v1")
 	{
-		v1.POST("/execute", handler.MakeExecuteHandler(execSvc))
+		v1.POST("/execute", middleware.OptionalAPIKeyAuth(), handler.MakeExecuteHandler(execSvc))
+		v1.POST("/execute/stream", middleware.OptionalAPIKeyAuth(), handler.MakeExecuteStreamHandler(execSvc))
+		v1.POST("/analyze", middleware.OptionalAPIKeyAuth(), handler.MakeAnalyzeHandler(execSvc))
+		v1.POST("/format", middleware.OptionalAPIKeyAuth(), handler.MakeFormatHandler(execSvc))
+		v1.GET("/languages", handler.MakeListLanguagesHandler(execSvc))
 		v1.GET("/problems", handler.MakeListProblemsHandler(problemService))
 		v1.GET("/problems/:id", handler.MakeGetProblemHandler(problemService))
 
+		executions := v1.Group("/executions")
+		{
+			executions.POST("", middleware.OptionalAPIKeyAuth(), executionJobHandler.Submit)
+			executions.GET("/:id", executionJobHandler.Get)
+			executions.DELETE("/:id", executionJobHandler.Cancel)
+		}
+
 		companies := v1.Group("/companies")
 		{
 			companies.POST("/register", companyHandler.Register)
@@ -37,6 +50,8 @@ func NewRouter(
 				auth.POST("/api-key", companyHandler.GenerateAPIKey)
 				auth.POST("/client-id", companyHandler.GenerateClientID)
 				auth.GET("/tests", codingTestHandler.GetCompanyTests)
+				auth.GET("/tests/:test_id/executions", executionHistoryHandler.ListTestExecutions)
+				auth.GET("/executions", executionHistoryHandler.ListCompanyExecutions)
 			}
 
 			apiAuth := companies.Group("")
@@ -2,6 +2,7 @@ package coding_test
 
 import (
 	"context"
+	"go-code-runner/internal/code_executor"
 	"go-code-runner/internal/models"
 )
 
@@ -9,6 +10,11 @@ type Service interface {
 	GenerateTest(ctx context.Context, companyID, problemID int, expiresInHours int) (*models.CodingTest, string, error)
 	VerifyTest(ctx context.Context, testID string) (*models.CodingTest, error)
 	StartTest(ctx context.Context, testID, candidateName, candidateEmail string) error
-	SubmitTest(ctx context.Context, testID, code string, passedPercentage int) error
+	SubmitTest(ctx context.Context, testID, code, language string, passedPercentage int) error
 	GetCompanyTests(ctx context.Context, companyID int) ([]*models.CodingTest, error)
-}
\ No newline at end of file
+}
+
+// Analyzer runs static analysis over a submission, see code_executor.Service.
+type Analyzer interface {
+	Analyze(ctx context.Context, code string, language string, opts ...code_executor.Option) ([]models.Diagnostic, error)
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"go-code-runner/internal/code_executor"
 	"go-code-runner/internal/models"
 	codingtestrepository "go-code-runner/internal/repository/coding_test"
 	companyrepository "go-code-runner/internal/repository/company"
 	problemrepository "go-code-runner/internal/repository/problems"
+	"log"
 	"time"
 )
 
@@ -16,14 +18,18 @@ type service struct {
 	repo              codingtestrepository.CodingTestRepository
 	problemRepository problemrepository.ProblemRepository
 	companyRepository companyrepository.Repository
+	analyzer          Analyzer
 	baseURL           string
 }
 
-func New(repo codingtestrepository.CodingTestRepository, problemRepository problemrepository.ProblemRepository, companyRepository companyrepository.Repository, baseURL string) Service {
+// New creates the coding test service. analyzer may be nil, in which case
+// submissions are stored without code analysis.
+func New(repo codingtestrepository.CodingTestRepository, problemRepository problemrepository.ProblemRepository, companyRepository companyrepository.Repository, analyzer Analyzer, baseURL string) Service {
 	return &service{
 		repo:              repo,
 		problemRepository: problemRepository,
 		companyRepository: companyRepository,
+		analyzer:          analyzer,
 		baseURL:           baseURL,
 	}
 }
@@ -110,7 +116,7 @@ func (s *service) StartTest(ctx context.Context, testID, candidateName, candidat
 	return s.repo.Update(ctx, test)
 }
 
-func (s *service) SubmitTest(ctx context.Context, testID, code string, passedPercentage int) error {
+func (s *service) SubmitTest(ctx context.Context, testID, code, language string, passedPercentage int) error {
 	test, err := s.repo.GetTestByID(ctx, testID)
 	if err != nil {
 		return fmt.Errorf("test not found: %w", err)
@@ -134,10 +140,29 @@ func (s *service) SubmitTest(ctx context.Context, testID, code string, passedPer
 	test.CompletedAt = &now
 	test.SubmissionCode = &code
 	test.PassedPercentage = &passedPercentage
+	test.CodeAnalysis = s.analyze(ctx, test, code, language)
 
 	return s.repo.Update(ctx, test)
 }
 
+// analyze returns the static analysis findings on a submission for the
+// interviewers. The analysis is best-effort: the submission is stored
+// without it if the language is unknown or unsupported or analysis fails.
+func (s *service) analyze(ctx context.Context, test *models.CodingTest, code, language string) []models.Diagnostic {
+	if s.analyzer == nil || language == "" {
+		return nil
+	}
+
+	diagnostics, err := s.analyzer.Analyze(ctx, code, language, code_executor.WithTenant(test.CompanyID))
+	if err != nil {
+		if !errors.Is(err, code_executor.ErrAnalysisUnsupported) {
+			log.Printf("code analysis of test %s failed: %v", test.ID, err)
+		}
+		return nil
+	}
+	return diagnostics
+}
+
 func (s *service) GetCompanyTests(ctx context.Context, companyID int) ([]*models.CodingTest, error) {
 	return s.repo.GetByCompanyID(ctx, companyID)
 }
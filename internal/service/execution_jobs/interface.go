@@ -0,0 +1,25 @@
+package execution_jobs
+
+import (
+	"context"
+	"errors"
+	"go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+var (
+	ErrJobNotFound = errors.New("execution job not found")
+	ErrJobFinished = errors.New("execution job has already finished")
+)
+
+type Service interface {
+	// Submit queues the code for execution with the same options as a
+	// synchronous run, e.g. files, version and tenant.
+	Submit(ctx context.Context, code, language string, problemID int, opts ...code_executor.Option) (*models.ExecutionJob, error)
+	Get(ctx context.Context, id string) (*models.ExecutionJob, error)
+	Cancel(ctx context.Context, id string) (*models.ExecutionJob, error)
+	// FailInterrupted marks the jobs a previous run of the server left
+	// queued or running as failed. It must be called before jobs are
+	// submitted.
+	FailInterrupted(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,224 @@
+package execution_jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+	jobrepository "go-code-runner/internal/repository/execution_jobs"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type service struct {
+	repo     jobrepository.ExecutionJobRepository
+	executor code_executor.Service
+	logger   *log.Logger
+
+	// workers holds a slot per running job; jobs stay queued until they
+	// get one.
+	workers chan struct{}
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+// New returns a job service that runs at most maxRunning jobs at once.
+func New(repo jobrepository.ExecutionJobRepository, executor code_executor.Service, logger *log.Logger, maxRunning int) Service {
+	if maxRunning <= 0 {
+		maxRunning = 1
+	}
+	return &service{
+		repo:     repo,
+		executor: executor,
+		logger:   logger,
+		workers:  make(chan struct{}, maxRunning),
+		running:  make(map[string]context.CancelFunc),
+	}
+}
+
+func (s *service) Submit(ctx context.Context, code, language string, problemID int, opts ...code_executor.Option) (*models.ExecutionJob, error) {
+	if _, err := s.executor.Language(language); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &models.ExecutionJob{
+		ID:          uuid.New().String(),
+		Language:    language,
+		Code:        code,
+		Status:      models.JobStatusQueued,
+		TestResults: []models.TestResult{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if problemID > 0 {
+		job.ProblemID = &problemID
+	}
+
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	// The job outlives the HTTP request that submitted it.
+	jobCtx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.running[job.ID] = cancel
+	s.mu.Unlock()
+
+	go s.run(jobCtx, *job, opts)
+
+	return job, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (*models.ExecutionJob, error) {
+	job, err := s.repo.GetJobByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	return job, err
+}
+
+func (s *service) Cancel(ctx context.Context, id string) (*models.ExecutionJob, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != models.JobStatusQueued && job.Status != models.JobStatusRunning {
+		return nil, ErrJobFinished
+	}
+
+	// Mark the job even if it is not running in this process any more, e.g.
+	// because the server restarted while it was in flight. The job may
+	// finish after it was read, and then keeps its result.
+	now := time.Now()
+	cancelled, err := s.repo.CancelJob(ctx, id, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if !cancelled {
+		return nil, ErrJobFinished
+	}
+
+	s.mu.Lock()
+	cancel, ok := s.running[id]
+	delete(s.running, id)
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+
+	job.Status = models.JobStatusCancelled
+	job.FinishedAt = &now
+	return job, nil
+}
+
+// FailInterrupted fails jobs whose goroutine died with the previous process,
+// as nothing would ever finish them.
+func (s *service) FailInterrupted(ctx context.Context) (int64, error) {
+	n, err := s.repo.FailUnfinishedJobs(ctx, "execution interrupted by a server restart")
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted jobs: %w", err)
+	}
+	return n, nil
+}
+
+func (s *service) run(ctx context.Context, job models.ExecutionJob, opts []code_executor.Option) {
+	defer func() {
+		s.mu.Lock()
+		if cancel, ok := s.running[job.ID]; ok {
+			cancel()
+			delete(s.running, job.ID)
+		}
+		s.mu.Unlock()
+	}()
+
+	select {
+	case s.workers <- struct{}{}:
+		defer func() { <-s.workers }()
+	case <-ctx.Done():
+		s.logger.Printf("execution job %s cancelled", job.ID)
+		return
+	}
+
+	startedAt := time.Now()
+	job.Status = models.JobStatusRunning
+	job.StartedAt = &startedAt
+	s.update(&job)
+
+	// Partial results are kept in test-case order even though test cases
+	// finish out of order.
+	finished := make(map[int]models.TestResult)
+	onEvent := func(e code_executor.Event) {
+		if e.Type != code_executor.EventTestCaseFinished || ctx.Err() != nil {
+			return
+		}
+		finished[e.Index] = *e.Result
+		job.TestResults = orderedResults(finished)
+		s.update(&job)
+	}
+
+	var err error
+	if job.ProblemID != nil {
+		var results *models.ExecutionResults
+		results, err = s.executor.ExecuteForProblem(ctx, job.Code, job.Language, *job.ProblemID, append(opts, code_executor.WithEvents(onEvent))...)
+		if err == nil {
+			job.Success = results.Success
+			job.CompileError = results.CompileError
+			job.TestResults = results.TestResults
+		}
+	} else {
+		var result *code_executor.ExecutionResult
+		result, err = s.executor.Execute(ctx, job.Code, job.Language, opts...)
+		if err == nil {
+			job.Success = result.Error == "" && result.CompileError == ""
+			job.Output = result.Output
+			job.Error = result.Error
+			job.CompileError = result.CompileError
+		}
+	}
+
+	if ctx.Err() != nil {
+		// Cancel already persisted the final state.
+		s.logger.Printf("execution job %s cancelled", job.ID)
+		return
+	}
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = models.JobStatusFinished
+	if err != nil {
+		job.Status = models.JobStatusFailed
+		job.Error = err.Error()
+	}
+	s.update(&job)
+}
+
+// update persists job progress. It uses a fresh context so that progress is
+// still recorded while the job context is being torn down.
+func (s *service) update(job *models.ExecutionJob) {
+	if err := s.repo.UpdateJob(context.Background(), job); err != nil {
+		s.logger.Printf("failed to update execution job %s: %v", job.ID, err)
+	}
+}
+
+func orderedResults(finished map[int]models.TestResult) []models.TestResult {
+	indexes := make([]int, 0, len(finished))
+	for i := range finished {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	results := make([]models.TestResult, 0, len(indexes))
+	for _, i := range indexes {
+		results = append(results, finished[i])
+	}
+	return results
+}
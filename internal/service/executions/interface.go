@@ -0,0 +1,18 @@
+package executions
+
+import (
+	"context"
+	"errors"
+	"go-code-runner/internal/models"
+)
+
+var ErrTestNotFound = errors.New("coding test not found")
+
+type Service interface {
+	// ListByCompany returns a page of the company's execution history and
+	// the total number of executions.
+	ListByCompany(ctx context.Context, companyID, limit, offset int) ([]*models.Execution, int, error)
+	// ListByTest returns a page of the execution history of one of the
+	// company's coding tests and the total number of executions.
+	ListByTest(ctx context.Context, companyID int, testID string, limit, offset int) ([]*models.Execution, int, error)
+}
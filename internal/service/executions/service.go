@@ -0,0 +1,35 @@
+package executions
+
+import (
+	"context"
+	"go-code-runner/internal/models"
+	codingtestrepository "go-code-runner/internal/repository/coding_test"
+	executionrepository "go-code-runner/internal/repository/executions"
+)
+
+type service struct {
+	repo           executionrepository.ExecutionRepository
+	codingTestRepo codingtestrepository.CodingTestRepository
+}
+
+func New(repo executionrepository.ExecutionRepository, codingTestRepo codingtestrepository.CodingTestRepository) Service {
+	return &service{
+		repo:           repo,
+		codingTestRepo: codingTestRepo,
+	}
+}
+
+func (s *service) ListByCompany(ctx context.Context, companyID, limit, offset int) ([]*models.Execution, int, error) {
+	return s.repo.ListExecutionsByCompany(ctx, companyID, limit, offset)
+}
+
+func (s *service) ListByTest(ctx context.Context, companyID int, testID string, limit, offset int) ([]*models.Execution, int, error) {
+	// Tests of other companies are reported as missing rather than
+	// forbidden, so their IDs cannot be probed.
+	test, err := s.codingTestRepo.GetTestByID(ctx, testID)
+	if err != nil || test.CompanyID != companyID {
+		return nil, 0, ErrTestNotFound
+	}
+
+	return s.repo.ListExecutionsByTest(ctx, testID, limit, offset)
+}
@@ -0,0 +1,79 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+func TestAnalyze(t *testing.T) {
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		switch {
+		case strings.HasPrefix(req.Script, "gofmt"):
+			return executor.FakeRun{Stdout: "main.go\n"}
+		case strings.Contains(req.Script, "go vet"):
+			return executor.FakeRun{
+				Stderr:   "# command-line-arguments\nvet: /app/main.go:3:8: \"os\" imported and not used\n./main.go:6:2: fmt.Printf format %d has arg s of wrong type string\n",
+				ExitCode: 1,
+			}
+		}
+		return executor.FakeRun{ExitCode: 127, Stderr: "not found"}
+	}}
+
+	diagnostics, err := newFakeService(sandbox).Analyze(context.Background(), "package main", "go")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	want := []models.Diagnostic{
+		{File: "main.go", Severity: "warning", Message: "file is not gofmt-formatted", Source: "gofmt"},
+		{File: "main.go", Line: 6, Column: 2, Severity: "warning", Message: "fmt.Printf format %d has arg s of wrong type string", Source: "vet"},
+		{File: "main.go", Line: 3, Column: 8, Severity: "error", Message: `"os" imported and not used`, Source: "vet"},
+	}
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("expected %+v, got %+v", want, diagnostics)
+	}
+	for _, run := range sandbox.Runs() {
+		if run.Writable {
+			t.Errorf("expected a read-only workspace for %q", run.Script)
+		}
+	}
+}
+
+func TestAnalyzeReportsFailedAnalyzers(t *testing.T) {
+	languages := executor.DefaultRegistry()
+	if err := languages.SetAnalyzers("go", []executor.Analyzer{{Name: "staticcheck", Cmd: "staticcheck ./..."}}); err != nil {
+		t.Fatalf("SetAnalyzers failed: %v", err)
+	}
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		return executor.FakeRun{Stderr: "sh: staticcheck: not found\n", ExitCode: 127}
+	}}
+	svc := executor.NewService(executor.Config{Sandbox: sandbox}, languages, log.New(io.Discard, "", 0), nil, nil)
+
+	diagnostics, err := svc.Analyze(context.Background(), "package main", "go")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	want := []models.Diagnostic{{Severity: "error", Message: "sh: staticcheck: not found", Source: "staticcheck"}}
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("expected %+v, got %+v", want, diagnostics)
+	}
+}
+
+func TestAnalyzeUnsupportedLanguage(t *testing.T) {
+	sandbox := &executor.FakeSandbox{}
+	_, err := newFakeService(sandbox).Analyze(context.Background(), "print(1)", "python")
+	if !errors.Is(err, executor.ErrAnalysisUnsupported) {
+		t.Fatalf("expected ErrAnalysisUnsupported, got %v", err)
+	}
+	if runs := sandbox.Runs(); len(runs) != 0 {
+		t.Errorf("expected no sandbox runs, got %d", len(runs))
+	}
+}
@@ -0,0 +1,243 @@
+package code_executor
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+func newCachedService(sandbox *executor.FakeSandbox, cache executor.ResultCache) executor.Service {
+	return executor.NewService(executor.Config{
+		ExecutionTimeout: 10 * time.Second,
+		Sandbox:          sandbox,
+		ResultCache:      cache,
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+}
+
+func TestExecuteWithTestCasesResultCache(t *testing.T) {
+	sandbox := &executor.FakeSandbox{Handler: echoSandbox}
+	cache := executor.NewResultCache(executor.ResultCacheConfig{}, log.New(io.Discard, "", 0))
+	svc := newCachedService(sandbox, cache)
+	testCases := []*models.TestCase{{ID: 1, Input: "42", ExpectedOutput: "42"}}
+
+	execute := func(code string, testCases []*models.TestCase, opts ...executor.Option) *models.ExecutionResults {
+		t.Helper()
+		results, err := svc.ExecuteWithTestCases(context.Background(), code, "python", testCases, opts...)
+		if err != nil {
+			t.Fatalf("ExecuteWithTestCases failed: %v", err)
+		}
+		return results
+	}
+
+	first := execute("print(input())", testCases)
+	if first.Cached {
+		t.Error("expected the first run not to be cached")
+	}
+	runs := len(sandbox.Runs())
+
+	var events []executor.Event
+	second := execute("print(input())", testCases, executor.WithEvents(func(e executor.Event) { events = append(events, e) }))
+	if !second.Cached {
+		t.Error("expected the second run to be served from the cache")
+	}
+	if len(sandbox.Runs()) != runs {
+		t.Errorf("expected no sandbox runs for a cache hit, got %d", len(sandbox.Runs())-runs)
+	}
+	if second.Verdict != first.Verdict || len(second.TestResults) != 1 || !second.TestResults[0].Passed {
+		t.Errorf("expected the cached results, got %+v", second)
+	}
+	if len(events) != 2 || events[0].Type != executor.EventTestCaseStarted || events[1].Type != executor.EventTestCaseFinished {
+		t.Errorf("expected a started and a finished event per cached test case, got %+v", events)
+	}
+
+	misses := []struct {
+		name      string
+		code      string
+		testCases []*models.TestCase
+		opts      []executor.Option
+	}{
+		{name: "Code", code: "print(input()) ", testCases: testCases},
+		{name: "ExpectedOutput", code: "print(input())", testCases: []*models.TestCase{{ID: 1, Input: "42", ExpectedOutput: "43"}}},
+		{name: "Limits", code: "print(input())", testCases: testCases, opts: []executor.Option{executor.WithLimits(executor.Limits{MemoryMB: 64})}},
+		{name: "Checker", code: "print(input())", testCases: testCases, opts: []executor.Option{executor.WithChecker(&models.Checker{Type: models.CheckerTokens})}},
+	}
+	for _, miss := range misses {
+		t.Run(miss.name, func(t *testing.T) {
+			if results := execute(miss.code, miss.testCases, miss.opts...); results.Cached {
+				t.Errorf("expected a change of %s to miss the cache", miss.name)
+			}
+		})
+	}
+}
+
+func TestExecuteWithTestCasesResultCacheEventOrder(t *testing.T) {
+	sandbox := &executor.FakeSandbox{Handler: echoSandbox}
+	cache := executor.NewResultCache(executor.ResultCacheConfig{}, log.New(io.Discard, "", 0))
+	svc := newCachedService(sandbox, cache)
+	testCases := []*models.TestCase{
+		{ID: 1, Input: "1", ExpectedOutput: "1"},
+		{ID: 2, Input: "2", ExpectedOutput: "3"},
+		{ID: 3, Input: "3", ExpectedOutput: "3"},
+	}
+
+	if _, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases); err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+
+	var events []executor.Event
+	results, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases, executor.WithEvents(func(e executor.Event) { events = append(events, e) }))
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+	if !results.Cached {
+		t.Fatal("expected the second run to be served from the cache")
+	}
+
+	if len(events) != 2*len(testCases) {
+		t.Fatalf("expected %d events, got %+v", 2*len(testCases), events)
+	}
+	for i, testCase := range testCases {
+		started, finished := events[2*i], events[2*i+1]
+		if started.Type != executor.EventTestCaseStarted || started.Index != i || started.TestCaseID != testCase.ID || started.Result != nil {
+			t.Errorf("event %d: expected test case %d to start, got %+v", 2*i, testCase.ID, started)
+		}
+		if finished.Type != executor.EventTestCaseFinished || finished.Index != i || finished.TestCaseID != testCase.ID || finished.Result == nil {
+			t.Errorf("event %d: expected test case %d to finish, got %+v", 2*i+1, testCase.ID, finished)
+			continue
+		}
+		if finished.Result.Passed != (testCase.ID != 2) {
+			t.Errorf("test case %d: expected the cached verdict, got %+v", testCase.ID, finished.Result)
+		}
+	}
+}
+
+func TestExecuteWithTestCasesResultCacheSkipsUnstableVerdicts(t *testing.T) {
+	for input, verdict := range map[string]models.Verdict{
+		"broken": models.VerdictInternalError,
+		"slow":   models.VerdictTimeLimitExceeded,
+		"oom":    models.VerdictMemoryLimitExceeded,
+	} {
+		t.Run(string(verdict), func(t *testing.T) {
+			sandbox := &executor.FakeSandbox{Handler: echoSandbox}
+			svc := newCachedService(sandbox, executor.NewResultCache(executor.ResultCacheConfig{}, log.New(io.Discard, "", 0)))
+			testCases := []*models.TestCase{{ID: 1, Input: input, ExpectedOutput: "42"}}
+
+			for i := 0; i < 2; i++ {
+				results, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases)
+				if err != nil {
+					t.Fatalf("ExecuteWithTestCases failed: %v", err)
+				}
+				if results.TestResults[0].Verdict != verdict {
+					t.Fatalf("expected %s, got %s", verdict, results.TestResults[0].Verdict)
+				}
+				if results.Cached {
+					t.Fatalf("expected results with %s not to be cached", verdict)
+				}
+			}
+		})
+	}
+}
+
+// memoryStore is a ResultStore backed by a map.
+type memoryStore struct {
+	mu      sync.Mutex
+	results map[string]*models.ExecutionResults
+	pruned  int
+}
+
+func (m *memoryStore) GetCachedResult(ctx context.Context, key string) (*models.ExecutionResults, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.results[key], time.Now().Add(time.Hour), nil
+}
+
+func (m *memoryStore) PutCachedResult(ctx context.Context, key string, results *models.ExecutionResults, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results[key] = results
+	return nil
+}
+
+func (m *memoryStore) PruneCachedResults(ctx context.Context, maxEntries int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruned++
+	return nil
+}
+
+func TestResultCache(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	results := &models.ExecutionResults{Success: true, Verdict: models.VerdictAccepted}
+
+	t.Run("EvictsLeastRecentlyUsed", func(t *testing.T) {
+		cache := executor.NewResultCache(executor.ResultCacheConfig{MaxEntries: 2}, logger)
+		cache.Put(ctx, "a", results)
+		cache.Put(ctx, "b", results)
+		cache.Get(ctx, "a")
+		cache.Put(ctx, "c", results)
+
+		if _, ok := cache.Get(ctx, "b"); ok {
+			t.Error("expected b to be evicted")
+		}
+		for _, key := range []string{"a", "c"} {
+			if _, ok := cache.Get(ctx, key); !ok {
+				t.Errorf("expected %s to be cached", key)
+			}
+		}
+	})
+
+	t.Run("Expires", func(t *testing.T) {
+		cache := executor.NewResultCache(executor.ResultCacheConfig{TTL: 10 * time.Millisecond}, logger)
+		cache.Put(ctx, "a", results)
+		time.Sleep(20 * time.Millisecond)
+		if _, ok := cache.Get(ctx, "a"); ok {
+			t.Error("expected a to have expired")
+		}
+	})
+
+	t.Run("ReturnsCopies", func(t *testing.T) {
+		cache := executor.NewResultCache(executor.ResultCacheConfig{}, logger)
+		cache.Put(ctx, "a", &models.ExecutionResults{
+			Diagnostics: []models.Diagnostic{{Message: "unused"}},
+			TestResults: []models.TestResult{{
+				TestCaseID:  1,
+				Diagnostics: []models.Diagnostic{{Message: "panic", Stack: []models.StackFrame{{Function: "main.main"}}}},
+				Usage:       &models.ResourceUsage{WallTimeMs: 10},
+			}},
+		})
+		got, _ := cache.Get(ctx, "a")
+		got.Diagnostics[0].Message = "changed"
+		got.TestResults[0].TestCaseID = 2
+		got.TestResults[0].Diagnostics[0].Message = "changed"
+		got.TestResults[0].Diagnostics[0].Stack[0].Function = "changed"
+		got.TestResults[0].Usage.WallTimeMs = 20
+
+		again, _ := cache.Get(ctx, "a")
+		testResult := again.TestResults[0]
+		if again.Diagnostics[0].Message != "unused" || testResult.TestCaseID != 1 ||
+			testResult.Diagnostics[0].Message != "panic" || testResult.Diagnostics[0].Stack[0].Function != "main.main" ||
+			testResult.Usage.WallTimeMs != 10 {
+			t.Errorf("expected changes to returned results not to affect the cache, got %+v", again)
+		}
+	})
+
+	t.Run("FallsBackToStore", func(t *testing.T) {
+		store := &memoryStore{results: make(map[string]*models.ExecutionResults)}
+		executor.NewResultCache(executor.ResultCacheConfig{Store: store}, logger).Put(ctx, "a", results)
+		if store.pruned != 1 {
+			t.Errorf("expected the store to be pruned once, got %d", store.pruned)
+		}
+
+		got, ok := executor.NewResultCache(executor.ResultCacheConfig{Store: store}, logger).Get(ctx, "a")
+		if !ok || got.Verdict != models.VerdictAccepted {
+			t.Errorf("expected the stored results, got %+v", got)
+		}
+	})
+}
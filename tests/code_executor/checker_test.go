@@ -0,0 +1,188 @@
+package code_executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+// runChecker executes a submission that prints each test case's input
+// against expected, judged by checker, and returns the test results.
+func runChecker(t *testing.T, sandbox *executor.FakeSandbox, checker *models.Checker, cases map[string]string) map[string]models.TestResult {
+	t.Helper()
+	svc := newFakeService(sandbox)
+
+	var testCases []*models.TestCase
+	for output, expected := range cases {
+		testCases = append(testCases, &models.TestCase{ID: len(testCases) + 1, Input: output, ExpectedOutput: expected})
+	}
+	results, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases, executor.WithChecker(checker))
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+
+	byOutput := make(map[string]models.TestResult)
+	for i, testResult := range results.TestResults {
+		byOutput[testCases[i].Input] = testResult
+	}
+	return byOutput
+}
+
+func TestCheckers(t *testing.T) {
+	cases := []struct {
+		name    string
+		checker *models.Checker
+		// accepted and rejected map outputs to the expected output.
+		accepted map[string]string
+		rejected map[string]string
+	}{
+		{
+			name:     "Tokens",
+			checker:  &models.Checker{Type: models.CheckerTokens},
+			accepted: map[string]string{"1  2\n3 ": "1 2 3", "\n\nyes": "yes"},
+			rejected: map[string]string{"1 2": "1 2 3", "1 3 2": "1 2 3"},
+		},
+		{
+			name:    "Float",
+			checker: &models.Checker{Type: models.CheckerFloat, AbsEpsilon: 1e-3},
+			accepted: map[string]string{
+				"3.1416 x": "3.14159 x",
+				"NaN":      "nan",
+				"+Inf":     "inf",
+				"-Inf":     "-inf",
+			},
+			rejected: map[string]string{
+				"3.15":   "3.14159",
+				"x 3.14": "y 3.14",
+				"NaN 1":  "0 1",
+				"0":      "NaN",
+				"Inf":    "-Inf",
+				"1e308":  "Inf",
+			},
+		},
+		{
+			name:     "FloatRelative",
+			checker:  &models.Checker{Type: models.CheckerFloat, RelEpsilon: 1e-2},
+			accepted: map[string]string{"1005": "1000"},
+			rejected: map[string]string{"0.02": "0.01"},
+		},
+		{
+			name:     "FloatDefaultEpsilon",
+			checker:  &models.Checker{Type: models.CheckerFloat},
+			accepted: map[string]string{"0.1000001": "0.1"},
+			rejected: map[string]string{"0.10001": "0.1"},
+		},
+		{
+			name:     "Unordered",
+			checker:  &models.Checker{Type: models.CheckerUnordered},
+			accepted: map[string]string{"b\na\n\n c": "a\nb\nc"},
+			rejected: map[string]string{"a\nb": "a\nb\nb", "a\nc": "a\nb"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			all := make(map[string]string)
+			for output, expected := range tc.accepted {
+				all[output] = expected
+			}
+			for output, expected := range tc.rejected {
+				all[output] = expected
+			}
+			results := runChecker(t, &executor.FakeSandbox{Handler: echoSandbox}, tc.checker, all)
+
+			for output, expected := range tc.accepted {
+				if got := results[output]; got.Verdict != models.VerdictAccepted {
+					t.Errorf("expected %q to match %q, got %s", output, expected, got.Verdict)
+				}
+			}
+			for output, expected := range tc.rejected {
+				got := results[output]
+				if got.Verdict != models.VerdictWrongAnswer {
+					t.Errorf("expected %q not to match %q, got %s", output, expected, got.Verdict)
+				}
+				if got.CheckerMessage == "" && tc.checker.Type != models.CheckerFloat {
+					t.Errorf("expected a message explaining why %q was rejected", output)
+				}
+			}
+		})
+	}
+}
+
+// checkerSandbox runs the submission like echoSandbox and answers checker
+// runs, recognized by their file arguments, by comparing the outputs case
+// insensitively.
+func checkerSandbox(req executor.RunRequest) executor.FakeRun {
+	args := strings.Fields(req.Script)
+	if len(args) < 3 || !strings.HasPrefix(args[len(args)-1], "actual-") {
+		return echoSandbox(req)
+	}
+	read := func(name string) string {
+		b, _ := os.ReadFile(filepath.Join(req.Dir, name))
+		return strings.TrimSpace(string(b))
+	}
+	expected, actual := read(args[len(args)-2]), read(args[len(args)-1])
+
+	switch expected {
+	case "checker-crash":
+		return executor.FakeRun{Stderr: "Traceback", ExitCode: 2}
+	case "checker-oom":
+		return executor.FakeRun{OOMKilled: true, ExitCode: 137}
+	case "checker-slow":
+		return executor.FakeRun{TimedOut: true, ExitCode: -1}
+	}
+	if strings.EqualFold(expected, actual) {
+		return executor.FakeRun{Stdout: "ok"}
+	}
+	return executor.FakeRun{Stdout: "differs", ExitCode: 1}
+}
+
+func TestCustomChecker(t *testing.T) {
+	sandbox := &executor.FakeSandbox{Handler: checkerSandbox}
+	checker := &models.Checker{Type: models.CheckerCustom, Language: "python", Code: "import sys"}
+
+	results := runChecker(t, sandbox, checker, map[string]string{
+		"YES": "yes",
+		"no":  "yes",
+		"x":   "checker-crash",
+		"y":   "checker-oom",
+		"z":   "checker-slow",
+	})
+
+	want := map[string]models.Verdict{
+		"YES": models.VerdictAccepted,
+		"no":  models.VerdictWrongAnswer,
+		"x":   models.VerdictInternalError,
+		"y":   models.VerdictInternalError,
+		"z":   models.VerdictInternalError,
+	}
+	for output, verdict := range want {
+		if got := results[output]; got.Verdict != verdict {
+			t.Errorf("%s: expected %s, got %s (%s)", output, verdict, got.Verdict, got.Error)
+		}
+	}
+	if msg := results["no"].CheckerMessage; msg != "differs" {
+		t.Errorf("expected the checker's message, got %q", msg)
+	}
+	if msg := results["y"].Error; !strings.Contains(msg, "memory limit") {
+		t.Errorf("expected the checker's memory limit in the error, got %q", msg)
+	}
+
+	// The checker lives in a workspace of its own.
+	var submissionDir, checkerDir string
+	for _, run := range sandbox.Runs() {
+		if strings.Contains(run.Script, "actual-") {
+			checkerDir = run.Dir
+		} else {
+			submissionDir = run.Dir
+		}
+	}
+	if checkerDir == "" || checkerDir == submissionDir {
+		t.Errorf("expected the checker to run outside the submission's workspace, got %q", checkerDir)
+	}
+}
@@ -0,0 +1,189 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+// concurrencyProbe is a sandbox handler that holds every run for a while and
+// records how many runs were in flight at once.
+type concurrencyProbe struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (p *concurrencyProbe) handle(req executor.RunRequest) executor.FakeRun {
+	p.mu.Lock()
+	p.running++
+	if p.running > p.peak {
+		p.peak = p.running
+	}
+	p.mu.Unlock()
+
+	input, _ := req.Input()
+	// Later test cases finish first, so results arrive out of order.
+	n, _ := strconv.Atoi(strings.TrimSpace(input))
+	time.Sleep(time.Duration(10-n) * 5 * time.Millisecond)
+
+	p.mu.Lock()
+	p.running--
+	p.mu.Unlock()
+	return executor.FakeRun{Stdout: input}
+}
+
+func (p *concurrencyProbe) maxRunning() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.peak
+}
+
+func newConcurrencyService(probe *concurrencyProbe, cfg executor.Config) executor.Service {
+	cfg.ExecutionTimeout = 10 * time.Second
+	cfg.Sandbox = &executor.FakeSandbox{Handler: probe.handle}
+	return executor.NewService(cfg, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+}
+
+func numberedTestCases(n int) []*models.TestCase {
+	testCases := make([]*models.TestCase, n)
+	for i := range testCases {
+		testCases[i] = &models.TestCase{ID: i + 1, Input: strconv.Itoa(i), ExpectedOutput: strconv.Itoa(i)}
+	}
+	return testCases
+}
+
+func TestExecuteWithTestCasesParallelCap(t *testing.T) {
+	probe := &concurrencyProbe{}
+	svc := newConcurrencyService(probe, executor.Config{MaxConcurrentSandboxes: 10, MaxParallelTestCases: 3})
+
+	testCases := numberedTestCases(8)
+	results, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases)
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+
+	if peak := probe.maxRunning(); peak != 3 {
+		t.Errorf("expected 3 test cases in parallel, got %d", peak)
+	}
+	if len(results.TestResults) != len(testCases) {
+		t.Fatalf("expected %d results, got %d", len(testCases), len(results.TestResults))
+	}
+	for i, testResult := range results.TestResults {
+		if testResult.TestCaseID != testCases[i].ID || !testResult.Passed {
+			t.Errorf("result %d: expected passed test case %d, got %d (%s)", i, testCases[i].ID, testResult.TestCaseID, testResult.Verdict)
+		}
+	}
+}
+
+func TestExecuteWithTestCasesGlobalSandboxCap(t *testing.T) {
+	probe := &concurrencyProbe{}
+	svc := newConcurrencyService(probe, executor.Config{MaxConcurrentSandboxes: 2, MaxParallelTestCases: 4})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", numberedTestCases(4))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("ExecuteWithTestCases failed: %v", err)
+		}
+	}
+	if peak := probe.maxRunning(); peak != 2 {
+		t.Errorf("expected at most 2 sandboxes across requests, got %d", peak)
+	}
+}
+
+func TestExecuteWithTestCasesQueueCancelled(t *testing.T) {
+	release := make(chan struct{})
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		<-release
+		return executor.FakeRun{}
+	}}
+	svc := executor.NewService(executor.Config{
+		ExecutionTimeout:       10 * time.Second,
+		MaxConcurrentSandboxes: 1,
+		Sandbox:                sandbox,
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+	defer close(release)
+
+	// Holds the only sandbox slot until the test ends.
+	go svc.Execute(context.Background(), "pass", "python")
+	waitFor(t, func() bool { return len(sandbox.Runs()) == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := svc.Execute(ctx, "pass", "python"); err == nil {
+		t.Error("expected a queued run to give up when its context ends")
+	}
+	if runs := len(sandbox.Runs()); runs != 1 {
+		t.Errorf("expected the queued run never to reach the sandbox, got %d runs", runs)
+	}
+}
+
+func TestExecuteWithTestCasesKeepsInputsOutOfWorkspace(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		leaked []string
+		inputs []string
+	)
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		entries, _ := os.ReadDir(req.Dir)
+		input, _ := req.Input()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, entry := range entries {
+			if entry.Name() != "main.py" {
+				leaked = append(leaked, entry.Name())
+			}
+		}
+		if strings.HasPrefix(req.StdinFile, req.Dir+string(filepath.Separator)) {
+			leaked = append(leaked, req.StdinFile)
+		}
+		inputs = append(inputs, req.StdinFile)
+		return executor.FakeRun{Stdout: input}
+	}}
+	svc := executor.NewService(executor.Config{
+		ExecutionTimeout:       10 * time.Second,
+		MaxConcurrentSandboxes: 4,
+		MaxParallelTestCases:   4,
+		Sandbox:                sandbox,
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+
+	results, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", numberedTestCases(6))
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+	if !results.Success {
+		t.Errorf("expected every test case to pass, got %+v", results.TestResults)
+	}
+	if len(leaked) > 0 {
+		t.Errorf("expected runs to see no inputs in the workspace, found %v", leaked)
+	}
+	for _, input := range inputs {
+		if _, err := os.Stat(input); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected input %s to be removed after its run, got %v", input, err)
+		}
+	}
+}
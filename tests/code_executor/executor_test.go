@@ -0,0 +1,346 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+func newFakeService(sandbox *executor.FakeSandbox) executor.Service {
+	return executor.NewService(executor.Config{
+		ExecutionTimeout:     10 * time.Second,
+		MaxParallelTestCases: 4,
+		Sandbox:              sandbox,
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+}
+
+// echoSandbox answers every run by the test case input it was given.
+func echoSandbox(req executor.RunRequest) executor.FakeRun {
+	input, _ := req.Input()
+	switch strings.TrimSpace(input) {
+	case "slow":
+		return executor.FakeRun{TimedOut: true, ExitCode: -1}
+	case "oom":
+		return executor.FakeRun{OOMKilled: true, ExitCode: 137}
+	case "crash":
+		return executor.FakeRun{Stderr: "boom", ExitCode: 1}
+	case "broken":
+		return executor.FakeRun{Err: errors.New("daemon unavailable")}
+	}
+	return executor.FakeRun{Stdout: input + "\n"}
+}
+
+func TestExecuteWithTestCasesVerdicts(t *testing.T) {
+	svc := newFakeService(&executor.FakeSandbox{Handler: echoSandbox})
+
+	testCases := []*models.TestCase{
+		{ID: 1, Input: "42", ExpectedOutput: "42"},
+		{ID: 2, Input: "41", ExpectedOutput: "42"},
+		{ID: 3, Input: "slow", ExpectedOutput: "42"},
+		{ID: 4, Input: "oom", ExpectedOutput: "42"},
+		{ID: 5, Input: "crash", ExpectedOutput: "42"},
+		{ID: 6, Input: "broken", ExpectedOutput: "42"},
+	}
+
+	results, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases)
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+
+	want := []models.Verdict{
+		models.VerdictAccepted,
+		models.VerdictWrongAnswer,
+		models.VerdictTimeLimitExceeded,
+		models.VerdictMemoryLimitExceeded,
+		models.VerdictRuntimeError,
+		models.VerdictInternalError,
+	}
+	if len(results.TestResults) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(results.TestResults))
+	}
+	for i, verdict := range want {
+		got := results.TestResults[i]
+		if got.TestCaseID != testCases[i].ID {
+			t.Errorf("result %d: expected test case %d, got %d", i, testCases[i].ID, got.TestCaseID)
+		}
+		if got.Verdict != verdict {
+			t.Errorf("test case %d: expected verdict %s, got %s (%s)", got.TestCaseID, verdict, got.Verdict, got.Error)
+		}
+		if got.Passed != (verdict == models.VerdictAccepted) {
+			t.Errorf("test case %d: unexpected Passed %v", got.TestCaseID, got.Passed)
+		}
+	}
+	if results.TestResults[4].Error != "boom" {
+		t.Errorf("expected stderr of the crash, got %q", results.TestResults[4].Error)
+	}
+
+	if results.Success {
+		t.Error("expected Success to be false")
+	}
+	if results.Verdict != models.VerdictWrongAnswer {
+		t.Errorf("expected overall verdict of the first failure, got %s", results.Verdict)
+	}
+}
+
+func TestExecuteWithTestCasesCompilationError(t *testing.T) {
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		if req.Script == req.Lang.CompileCmd {
+			return executor.FakeRun{Stderr: "./main.go:3:1: syntax error", ExitCode: 1}
+		}
+		return executor.FakeRun{}
+	}}
+	svc := newFakeService(sandbox)
+
+	testCases := []*models.TestCase{{ID: 1, Input: "1", ExpectedOutput: "1"}}
+	results, err := svc.ExecuteWithTestCases(context.Background(), "package main\nfunc main() {", "go", testCases)
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+
+	if results.Verdict != models.VerdictCompilationError {
+		t.Errorf("expected compilation error, got %s", results.Verdict)
+	}
+	if !strings.Contains(results.CompileError, "syntax error") {
+		t.Errorf("expected compiler output, got %q", results.CompileError)
+	}
+	if runs := sandbox.Runs(); len(runs) != 1 {
+		t.Errorf("expected only the compile run, got %d runs", len(runs))
+	}
+}
+
+func TestExecuteWithTestCasesCompilesOnce(t *testing.T) {
+	sandbox := &executor.FakeSandbox{Handler: echoSandbox}
+	svc := newFakeService(sandbox)
+
+	testCases := []*models.TestCase{
+		{ID: 1, Input: "1", ExpectedOutput: "1"},
+		{ID: 2, Input: "2", ExpectedOutput: "2"},
+		{ID: 3, Input: "3", ExpectedOutput: "3"},
+	}
+	results, err := svc.ExecuteWithTestCases(context.Background(), "package main\nfunc main() {}", "go", testCases)
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+	if !results.Success {
+		t.Errorf("expected every test case to pass, got %+v", results.TestResults)
+	}
+
+	runs := sandbox.Runs()
+	if len(runs) != 1+len(testCases) {
+		t.Fatalf("expected a compile and a run per test case, got %d runs", len(runs))
+	}
+	if runs[0].Script != runs[0].Lang.CompileCmd || !runs[0].Writable {
+		t.Errorf("expected the first run to build the submission, got %q (writable: %v)", runs[0].Script, runs[0].Writable)
+	}
+	for _, run := range runs[1:] {
+		if run.Script != run.Lang.RunCmd || run.Writable {
+			t.Errorf("expected the built binary to run read-only, got %q (writable: %v)", run.Script, run.Writable)
+		}
+		if run.Dir != runs[0].Dir {
+			t.Errorf("expected every run in the build's workspace, got %s and %s", run.Dir, runs[0].Dir)
+		}
+	}
+}
+
+func TestExecuteWithTestCasesReportsCompilationErrorOnce(t *testing.T) {
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		return executor.FakeRun{Stderr: "./main.go:1:1: expected 'package'", ExitCode: 1}
+	}}
+	svc := newFakeService(sandbox)
+
+	testCases := []*models.TestCase{{ID: 1, Input: "1"}, {ID: 2, Input: "2"}}
+	results, err := svc.ExecuteWithTestCases(context.Background(), "func main() {}", "go", testCases)
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+	if results.CompileError == "" || len(results.TestResults) != 0 {
+		t.Errorf("expected a single compile error without test results, got %q and %+v", results.CompileError, results.TestResults)
+	}
+}
+
+func TestExecuteWithTestCasesHidesHiddenCases(t *testing.T) {
+	svc := newFakeService(&executor.FakeSandbox{Handler: echoSandbox})
+
+	testCases := []*models.TestCase{{ID: 1, Input: "7", ExpectedOutput: "7", IsHidden: true}}
+	results, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases)
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+
+	got := results.TestResults[0]
+	if !got.Passed {
+		t.Errorf("expected hidden test case to pass, got %s", got.Verdict)
+	}
+	if got.Input != "" || got.ExpectedOutput != "" {
+		t.Errorf("expected hidden input and output to be cleared, got %q and %q", got.Input, got.ExpectedOutput)
+	}
+}
+
+func TestExecuteWithTestCasesAppliesLimits(t *testing.T) {
+	sandbox := &executor.FakeSandbox{}
+	svc := newFakeService(sandbox)
+
+	testCases := []*models.TestCase{{ID: 1, Input: "", ExpectedOutput: ""}}
+	_, err := svc.ExecuteWithTestCases(context.Background(), "pass", "python", testCases,
+		executor.WithLimits(executor.Limits{TimeLimit: time.Second, MemoryMB: 64}))
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+
+	runs := sandbox.Runs()
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(runs))
+	}
+	// Python runs with a time multiplier of 3.
+	if runs[0].Limits.TimeLimit != 3*time.Second {
+		t.Errorf("expected a 3s time limit, got %v", runs[0].Limits.TimeLimit)
+	}
+	if runs[0].Limits.MemoryMB != 64 {
+		t.Errorf("expected a 64 MB memory limit, got %d", runs[0].Limits.MemoryMB)
+	}
+}
+
+func TestExecuteWithTestCasesScalesDefaultTimeLimit(t *testing.T) {
+	cases := []struct {
+		language string
+		want     time.Duration
+	}{
+		// newFakeService runs with a 10s execution timeout.
+		{language: "python", want: 30 * time.Second},
+		{language: "javascript", want: 20 * time.Second},
+		{language: "go", want: 10 * time.Second},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.language, func(t *testing.T) {
+			sandbox := &executor.FakeSandbox{}
+			svc := newFakeService(sandbox)
+
+			testCases := []*models.TestCase{{ID: 1, Input: "", ExpectedOutput: ""}}
+			if _, err := svc.ExecuteWithTestCases(context.Background(), "code", tc.language, testCases); err != nil {
+				t.Fatalf("ExecuteWithTestCases failed: %v", err)
+			}
+
+			runs := sandbox.Runs()
+			last := runs[len(runs)-1]
+			if last.Limits.TimeLimit != tc.want {
+				t.Errorf("expected a %v time limit, got %v", tc.want, last.Limits.TimeLimit)
+			}
+		})
+	}
+}
+
+func TestExecuteWithTestCasesOutputLimit(t *testing.T) {
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		// "é" is cut in half by the limit.
+		return executor.FakeRun{Stdout: strings.Repeat("x", 9) + "é" + strings.Repeat("x", 100)}
+	}}
+	svc := executor.NewService(executor.Config{
+		ExecutionTimeout: 10 * time.Second,
+		MaxOutputBytes:   10,
+		Sandbox:          sandbox,
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+
+	testCases := []*models.TestCase{{ID: 1, Input: "", ExpectedOutput: "x"}}
+	results, err := svc.ExecuteWithTestCases(context.Background(), "pass", "python", testCases)
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+
+	got := results.TestResults[0]
+	if got.Verdict != models.VerdictOutputLimitExceeded || !got.Truncated {
+		t.Errorf("expected a truncated output limit exceeded, got %s (truncated: %v)", got.Verdict, got.Truncated)
+	}
+	if got.ActualOutput != strings.Repeat("x", 9) {
+		t.Errorf("expected the output up to the last whole rune, got %q", got.ActualOutput)
+	}
+}
+
+func TestExecuteCompileDiagnostics(t *testing.T) {
+	cases := []struct {
+		language string
+		stderr   string
+		want     models.Diagnostic
+	}{
+		{
+			language: "go",
+			stderr:   "# command-line-arguments\n./main.go:3:14: undefined: x\n",
+			want:     models.Diagnostic{File: "main.go", Line: 3, Column: 14, Severity: "error", Message: "undefined: x"},
+		},
+		{
+			language: "cpp",
+			stderr:   "/app/main.cpp: In function 'int main()':\n/app/main.cpp:4:5: warning: unused variable 'y'\n",
+			want:     models.Diagnostic{File: "main.cpp", Line: 4, Column: 5, Severity: "warning", Message: "unused variable 'y'"},
+		},
+		{
+			language: "java",
+			stderr:   "Main.java:2: error: ';' expected\n    int x = 1\n             ^\n1 error\n",
+			want:     models.Diagnostic{File: "Main.java", Line: 2, Column: 14, Severity: "error", Message: "';' expected"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.language, func(t *testing.T) {
+			sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+				return executor.FakeRun{Stderr: tc.stderr, ExitCode: 1}
+			}}
+			result, err := newFakeService(sandbox).Execute(context.Background(), "code", tc.language)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if strings.Contains(result.CompileError, "/app/") {
+				t.Errorf("expected paths relative to the workspace, got %q", result.CompileError)
+			}
+			if len(result.Diagnostics) != 1 || !reflect.DeepEqual(result.Diagnostics[0], tc.want) {
+				t.Errorf("expected diagnostic %+v, got %+v", tc.want, result.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestExecuteGoPanicDiagnostics(t *testing.T) {
+	stderr := `panic: runtime error: index out of range [5] with length 3
+
+goroutine 1 [running]:
+main.lookup(...)
+	/app/main.go:8
+main.main()
+	/app/main.go:12 +0x1d
+exit status 2
+`
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		if req.Script == req.Lang.CompileCmd {
+			return executor.FakeRun{}
+		}
+		return executor.FakeRun{Stderr: stderr, ExitCode: 2}
+	}}
+	result, err := newFakeService(sandbox).Execute(context.Background(), "package main", "go")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Verdict != models.VerdictRuntimeError {
+		t.Fatalf("expected a runtime error, got %s", result.Verdict)
+	}
+
+	want := []models.Diagnostic{{
+		File:     "main.go",
+		Line:     8,
+		Severity: "error",
+		Message:  "panic: runtime error: index out of range [5] with length 3",
+		Stack: []models.StackFrame{
+			{Function: "main.lookup", File: "main.go", Line: 8},
+			{Function: "main.main", File: "main.go", Line: 12},
+		},
+	}}
+	if !reflect.DeepEqual(result.Diagnostics, want) {
+		t.Errorf("expected %+v, got %+v", want, result.Diagnostics)
+	}
+}
@@ -0,0 +1,37 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	executor "go-code-runner/internal/code_executor"
+)
+
+func TestExecuteRejectsInvalidFiles(t *testing.T) {
+	svc := executor.NewService(executor.Config{MaxSubmissionBytes: 1024}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+
+	tests := []struct {
+		name  string
+		code  string
+		files map[string]string
+	}{
+		{name: "ParentEscape", files: map[string]string{"main.py": "print(1)", "../escape.py": ""}},
+		{name: "NestedEscape", files: map[string]string{"main.py": "print(1)", "pkg/../../escape.py": ""}},
+		{name: "AbsolutePath", files: map[string]string{"main.py": "print(1)", "/etc/passwd": ""}},
+		{name: "TooLarge", files: map[string]string{"main.py": strings.Repeat("#", 2048)}},
+		{name: "MissingMainSource", files: map[string]string{"helper.py": "X = 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Execute(context.Background(), tt.code, "python", executor.WithFiles(tt.files))
+			if !errors.Is(err, executor.ErrInvalidFiles) {
+				t.Fatalf("expected ErrInvalidFiles, got %v", err)
+			}
+		})
+	}
+}
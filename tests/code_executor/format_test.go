@@ -0,0 +1,59 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+func TestFormat(t *testing.T) {
+	const formatted = "package main\n\nfunc main() {\n\tprintln(1)\n}\n"
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		return executor.FakeRun{Stdout: formatted}
+	}}
+
+	result, err := newFakeService(sandbox).Format(context.Background(), "package main\nfunc main() { println(1) }", "golang")
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if result.Code != formatted {
+		t.Errorf("expected %q, got %q", formatted, result.Code)
+	}
+	if len(result.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %+v", result.Diagnostics)
+	}
+
+	runs := sandbox.Runs()
+	if len(runs) != 1 || runs[0].Script != "gofmt main.go" || runs[0].Writable {
+		t.Errorf("expected one read-only gofmt run, got %+v", runs)
+	}
+}
+
+func TestFormatSyntaxError(t *testing.T) {
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		return executor.FakeRun{Stderr: "/app/main.go:2:15: expected '}', found 'EOF'\n", ExitCode: 2}
+	}}
+
+	result, err := newFakeService(sandbox).Format(context.Background(), "package main\nfunc main() {", "go")
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if result.Code != "" {
+		t.Errorf("expected no code, got %q", result.Code)
+	}
+	want := []models.Diagnostic{{File: "main.go", Line: 2, Column: 15, Severity: "error", Message: "expected '}', found 'EOF'"}}
+	if !reflect.DeepEqual(result.Diagnostics, want) {
+		t.Errorf("expected %+v, got %+v", want, result.Diagnostics)
+	}
+}
+
+func TestFormatUnsupportedLanguage(t *testing.T) {
+	_, err := newFakeService(&executor.FakeSandbox{}).Format(context.Background(), "print(1)", "python")
+	if !errors.Is(err, executor.ErrFormatUnsupported) {
+		t.Fatalf("expected ErrFormatUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package code_executor
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+func TestParseModules(t *testing.T) {
+	modules, err := executor.ParseModules([]string{"github.com/google/go-cmp@v0.6.0", " golang.org/x/exp@v0.0.0-20240506185415-9bf2ced13842 ", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(modules) != 2 {
+		t.Fatalf("expected 2 modules, got %d", len(modules))
+	}
+	if modules[0].Path != "github.com/google/go-cmp" || modules[0].Version != "v0.6.0" {
+		t.Errorf("unexpected module %+v", modules[0])
+	}
+
+	if _, err := executor.ParseModules([]string{"github.com/google/go-cmp"}); err == nil {
+		t.Error("expected error for module without version, got nil")
+	}
+}
+
+func TestSeedGoModulesReportsCacheDirErrors(t *testing.T) {
+	// A file where the cache directory belongs keeps it from being created.
+	name := "seed-cache-" + filepath.Base(t.TempDir())
+	blocker := filepath.Join("/tmp/runbox", name)
+	if err := os.MkdirAll(filepath.Dir(blocker), 0755); err != nil {
+		t.Fatalf("failed to create base dir: %v", err)
+	}
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("failed to create blocker: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(blocker) })
+
+	lang := &executor.Language{Name: "go", Image: "golang:1.22", Caches: []executor.CacheMount{{Name: name, Target: "/cache"}}}
+	modules := []executor.Module{{Path: "github.com/google/go-cmp", Version: "v0.6.0"}}
+
+	// The Docker client is never reached.
+	err := executor.SeedGoModules(context.Background(), nil, lang, t.TempDir(), modules, log.New(io.Discard, "", 0))
+	if err == nil || !strings.Contains(err.Error(), name) {
+		t.Fatalf("expected an error creating the cache directory, got %v", err)
+	}
+}
+
+func TestExecuteRejectsDisallowedImports(t *testing.T) {
+	modules, _ := executor.ParseModules([]string{"github.com/google/go-cmp@v0.6.0"})
+	svc := executor.NewService(executor.Config{AllowedModules: modules}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+
+	code := "package main\n\nimport \"github.com/pkg/errors\"\n\nfunc main() { _ = errors.New(\"x\") }\n"
+	result, err := svc.Execute(context.Background(), code, "go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Verdict != models.VerdictCompilationError {
+		t.Fatalf("expected compilation error, got %s", result.Verdict)
+	}
+	if !strings.Contains(result.CompileError, "github.com/pkg/errors") || !strings.Contains(result.CompileError, "github.com/google/go-cmp@v0.6.0") {
+		t.Errorf("expected error to name the import and the allowed modules, got %q", result.CompileError)
+	}
+
+	files := map[string]string{
+		"go.mod":  "module example.com/solution\n\ngo 1.22\n\nrequire github.com/pkg/errors v0.9.1\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	result, err = svc.Execute(context.Background(), "", "go", executor.WithFiles(files))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Verdict != models.VerdictCompilationError {
+		t.Fatalf("expected compilation error for disallowed go.mod requirement, got %s", result.Verdict)
+	}
+}
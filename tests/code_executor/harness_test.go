@@ -0,0 +1,185 @@
+package code_executor
+
+import (
+	"context"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+var twoSumSignature = &models.FunctionSignature{
+	Name: "twoSum",
+	Params: []models.FunctionParam{
+		{Name: "nums", Type: "int[]"},
+		{Name: "target", Type: "int"},
+	},
+	ReturnType: "int[]",
+}
+
+func harness(t *testing.T, language string) executor.HarnessFunc {
+	t.Helper()
+	lang, err := executor.DefaultRegistry().Get(language)
+	if err != nil {
+		t.Fatalf("failed to get %s: %v", language, err)
+	}
+	if lang.Harness == nil {
+		t.Fatalf("expected %s to support function problems", language)
+	}
+	return lang.Harness
+}
+
+func TestGoHarness(t *testing.T) {
+	generate := harness(t, "go")
+
+	t.Run("KeepsLineNumbers", func(t *testing.T) {
+		code := "package main\n\nfunc twoSum(nums []int, target int) []int {\n\treturn nil\n}\n"
+		src, err := generate(twoSumSignature, code)
+		if err != nil {
+			t.Fatalf("harness failed: %v", err)
+		}
+		lines := strings.Split(src, "\n")
+		if !strings.HasPrefix(lines[2], "func twoSum(") {
+			t.Errorf("expected the candidate's function to stay on line 3, got %q", lines[2])
+		}
+		for _, want := range []string{"var __arg0 []int", "var __arg1 int", "twoSum(__arg0, __arg1)"} {
+			if !strings.Contains(src, want) {
+				t.Errorf("expected the harness to contain %q:\n%s", want, src)
+			}
+		}
+	})
+
+	t.Run("AddsMissingPackageClause", func(t *testing.T) {
+		src, err := generate(twoSumSignature, "func twoSum(nums []int, target int) []int { return nil }")
+		if err != nil {
+			t.Fatalf("harness failed: %v", err)
+		}
+		if !strings.HasPrefix(src, "package main;") {
+			t.Errorf("expected a package clause on the first line, got %q", strings.SplitN(src, "\n", 2)[0])
+		}
+	})
+
+	t.Run("RejectsOtherPackages", func(t *testing.T) {
+		if _, err := generate(twoSumSignature, "package solution\n\nfunc twoSum() {}"); err == nil {
+			t.Error("expected a package other than main to be rejected")
+		}
+	})
+}
+
+func TestScriptHarnesses(t *testing.T) {
+	for language, want := range map[string]string{
+		"python":     "print(__json.dumps(twoSum(*__args)",
+		"javascript": "console.log(JSON.stringify(twoSum(...__args)));",
+	} {
+		t.Run(language, func(t *testing.T) {
+			code := "// candidate code"
+			src, err := harness(t, language)(twoSumSignature, code)
+			if err != nil {
+				t.Fatalf("harness failed: %v", err)
+			}
+			if !strings.HasPrefix(src, code) {
+				t.Errorf("expected the candidate's code first, got %q", src)
+			}
+			if !strings.Contains(src, want) {
+				t.Errorf("expected the harness to contain %q:\n%s", want, src)
+			}
+		})
+	}
+}
+
+func TestFunctionProblemRejectsInvalidSignature(t *testing.T) {
+	svc := newFakeService(&executor.FakeSandbox{})
+	sig := &models.FunctionSignature{Name: "f", Params: []models.FunctionParam{{Name: "x", Type: "map"}}, ReturnType: "int"}
+
+	_, err := svc.ExecuteWithTestCases(context.Background(), "def f(x): return 1", "python",
+		[]*models.TestCase{{ID: 1, Input: "1", ExpectedOutput: "1"}}, executor.WithFunctionSignature(sig))
+	if err == nil || !strings.Contains(err.Error(), "invalid type") {
+		t.Errorf("expected the invalid parameter type to be rejected, got %v", err)
+	}
+}
+
+// TestFunctionProblemsLocal runs generated harnesses with the toolchains of
+// the host, skipping languages that are not installed.
+func TestFunctionProblemsLocal(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	sandbox, err := executor.NewLocalSandbox(executor.LocalSandboxConfig{}, logger)
+	if err != nil {
+		t.Skipf("local sandbox unavailable: %v", err)
+	}
+	svc := executor.NewService(executor.Config{
+		ExecutionTimeout: time.Minute,
+		Sandbox:          sandbox,
+	}, executor.DefaultRegistry(), logger, nil, nil)
+
+	solutions := []struct {
+		language string
+		binary   string
+		code     string
+	}{
+		{
+			language: "go",
+			binary:   "go",
+			code: `package main
+
+func twoSum(nums []int, target int) []int {
+	for i := range nums {
+		for j := i + 1; j < len(nums); j++ {
+			if nums[i]+nums[j] == target {
+				return []int{i, j}
+			}
+		}
+	}
+	return nil
+}
+`,
+		},
+		{
+			language: "python",
+			binary:   "python3",
+			code: `def twoSum(nums, target):
+    seen = {}
+    for i, n in enumerate(nums):
+        if target - n in seen:
+            return [seen[target - n], i]
+        seen[n] = i
+`,
+		},
+		{
+			language: "javascript",
+			binary:   "node",
+			code: `function twoSum(nums, target) {
+  const seen = new Map();
+  for (let i = 0; i < nums.length; i++) {
+    if (seen.has(target - nums[i])) return [seen.get(target - nums[i]), i];
+    seen.set(nums[i], i);
+  }
+}
+`,
+		},
+	}
+
+	testCases := []*models.TestCase{
+		{ID: 1, Input: "[2,7,11,15]\n9", ExpectedOutput: "[0,1]"},
+		{ID: 2, Input: "[3,2,4]\n6", ExpectedOutput: "[1, 2]"},
+	}
+	for _, solution := range solutions {
+		t.Run(solution.language, func(t *testing.T) {
+			if _, err := exec.LookPath(solution.binary); err != nil {
+				t.Skipf("%s not installed", solution.binary)
+			}
+			results, err := svc.ExecuteWithTestCases(context.Background(), solution.code, solution.language, testCases,
+				executor.WithFunctionSignature(twoSumSignature), executor.WithChecker(&models.Checker{Type: models.CheckerJSON}))
+			if err != nil {
+				t.Fatalf("ExecuteWithTestCases failed: %v", err)
+			}
+			if !results.Success {
+				t.Errorf("expected every test case to pass, got %s (%s) %+v", results.Verdict, results.CompileError, results.TestResults)
+			}
+		})
+	}
+}
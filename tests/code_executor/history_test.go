@@ -0,0 +1,294 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeHistory keeps the recorded executions in memory.
+type fakeHistory struct {
+	mu         sync.Mutex
+	executions []*models.Execution
+	err        error
+}
+
+func (h *fakeHistory) CreateExecution(_ context.Context, execution *models.Execution) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.executions = append(h.executions, execution)
+	return h.err
+}
+
+func (h *fakeHistory) recorded() []*models.Execution {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*models.Execution(nil), h.executions...)
+}
+
+// fakeCodingTests serves coding tests from memory.
+type fakeCodingTests map[string]*models.CodingTest
+
+func (f fakeCodingTests) GetTestByID(_ context.Context, id string) (*models.CodingTest, error) {
+	test, ok := f[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return test, nil
+}
+
+// codingTests holds test-1 of company 3, started a minute ago.
+func codingTests() fakeCodingTests {
+	started := time.Now().Add(-time.Minute)
+	return fakeCodingTests{"test-1": {
+		ID:                  "test-1",
+		CompanyID:           3,
+		Status:              models.TestStatusStarted,
+		StartedAt:           &started,
+		ExpiresAt:           time.Now().Add(time.Hour),
+		TestDurationMinutes: 60,
+	}}
+}
+
+func newRecordingService(sandbox *executor.FakeSandbox, history executor.ExecutionHistory, cache executor.ResultCache) executor.Service {
+	return executor.NewService(executor.Config{
+		ExecutionTimeout: 10 * time.Second,
+		Sandbox:          sandbox,
+		ResultCache:      cache,
+		History:          history,
+		CodingTests:      codingTests(),
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+}
+
+func TestExecuteWithTestCasesRecordsExecution(t *testing.T) {
+	history := &fakeHistory{}
+	cache := executor.NewResultCache(executor.ResultCacheConfig{}, log.New(io.Discard, "", 0))
+	svc := newRecordingService(&executor.FakeSandbox{Handler: echoSandbox}, history, cache)
+	testCases := []*models.TestCase{
+		{ID: 1, Input: "42", ExpectedOutput: "42"},
+		{ID: 2, Input: "7", ExpectedOutput: "8"},
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases,
+			executor.WithTenant(3), executor.WithCodingTest("test-1"))
+		if err != nil {
+			t.Fatalf("ExecuteWithTestCases failed: %v", err)
+		}
+	}
+
+	executions := history.recorded()
+	if len(executions) != 2 {
+		t.Fatalf("expected an execution recorded per run, got %d", len(executions))
+	}
+	first := executions[0]
+	if first.Language != "python" || first.CodeHash == "" || first.Verdict != models.VerdictWrongAnswer {
+		t.Errorf("unexpected execution %+v", first)
+	}
+	if first.CompanyID == nil || *first.CompanyID != 3 || first.TestID == nil || *first.TestID != "test-1" || first.ProblemID != nil {
+		t.Errorf("expected the execution attributed to company 3 and test-1, got %+v", first)
+	}
+	if len(first.TestCases) != 2 || first.TestCases[0].Verdict != models.VerdictAccepted || first.TestCases[1].Verdict != models.VerdictWrongAnswer {
+		t.Errorf("expected a verdict per test case, got %+v", first.TestCases)
+	}
+	if first.StartedAt.IsZero() || first.FinishedAt.Before(first.StartedAt) {
+		t.Errorf("expected the run's timings, got %v to %v", first.StartedAt, first.FinishedAt)
+	}
+	if first.Cached || !executions[1].Cached {
+		t.Errorf("expected only the rerun to be served from the cache, got %v and %v", first.Cached, executions[1].Cached)
+	}
+	if executions[1].CodeHash != first.CodeHash {
+		t.Errorf("expected the same code to hash the same, got %q and %q", first.CodeHash, executions[1].CodeHash)
+	}
+}
+
+func TestExecuteRecordsExecution(t *testing.T) {
+	history := &fakeHistory{}
+	svc := newRecordingService(&executor.FakeSandbox{Handler: echoSandbox}, history, nil)
+
+	if _, err := svc.Execute(context.Background(), "print('hi')", "python"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := svc.Execute(context.Background(), "print('hi')", "cobol"); err == nil {
+		t.Fatal("expected an unsupported language to fail")
+	}
+
+	executions := history.recorded()
+	if len(executions) != 2 {
+		t.Fatalf("expected both runs to be recorded, got %d", len(executions))
+	}
+	if executions[0].Verdict != models.VerdictAccepted || executions[0].CompanyID != nil || executions[0].Error != "" {
+		t.Errorf("unexpected execution %+v", executions[0])
+	}
+	if executions[1].Language != "cobol" || executions[1].Verdict != "" || executions[1].Error == "" {
+		t.Errorf("expected the unknown language recorded with its error, got %+v", executions[1])
+	}
+}
+
+func TestExecuteChecksCodingTest(t *testing.T) {
+	expired := codingTests()
+	expired["test-1"].ExpiresAt = time.Now().Add(-time.Second)
+	overdue := codingTests()
+	overdue["test-1"].TestDurationMinutes = 1
+	pending := codingTests()
+	pending["test-1"].Status = models.TestStatusPending
+
+	tests := []struct {
+		name   string
+		tests  fakeCodingTests
+		testID string
+		tenant int
+		valid  bool
+	}{
+		{"Tenant", codingTests(), "test-1", 3, true},
+		{"OtherTenant", codingTests(), "test-1", 4, false},
+		{"Candidate", codingTests(), "test-1", 0, true},
+		{"Pending", pending, "test-1", 0, false},
+		{"Overdue", overdue, "test-1", 0, false},
+		{"Expired", expired, "test-1", 0, false},
+		{"Unknown", codingTests(), "test-2", 3, false},
+		{"TooLong", codingTests(), strings.Repeat("a", 37), 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := &fakeHistory{}
+			svc := executor.NewService(executor.Config{
+				ExecutionTimeout: 10 * time.Second,
+				Sandbox:          &executor.FakeSandbox{Handler: echoSandbox},
+				History:          history,
+				CodingTests:      tt.tests,
+			}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+
+			_, err := svc.Execute(context.Background(), "print('hi')", "python",
+				executor.WithTenant(tt.tenant), executor.WithCodingTest(tt.testID))
+			if tt.valid && err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, executor.ErrInvalidCodingTest) {
+				t.Fatalf("expected ErrInvalidCodingTest, got %v", err)
+			}
+
+			executions := history.recorded()
+			if len(executions) != 1 {
+				t.Fatalf("expected the run to be recorded, got %d", len(executions))
+			}
+			attributed := executions[0].TestID != nil
+			if attributed != tt.valid {
+				t.Errorf("expected attribution %v, got %+v", tt.valid, executions[0].TestID)
+			}
+		})
+	}
+}
+
+func TestExecuteIgnoresHistoryFailures(t *testing.T) {
+	history := &fakeHistory{err: errors.New("database unavailable")}
+	svc := newRecordingService(&executor.FakeSandbox{Handler: echoSandbox}, history, nil)
+
+	results, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python",
+		[]*models.TestCase{{ID: 1, Input: "42", ExpectedOutput: "42"}})
+	if err != nil {
+		t.Fatalf("expected the execution to succeed despite the history, got %v", err)
+	}
+	if !results.Success || len(history.recorded()) != 1 {
+		t.Errorf("expected a successful, attempted record, got %+v", results)
+	}
+}
+
+func TestExecuteRunsCodingTestForItsCompany(t *testing.T) {
+	history := &fakeHistory{}
+	sandbox := &executor.FakeSandbox{Handler: echoSandbox}
+	svc := executor.NewService(executor.Config{
+		ExecutionTimeout: 10 * time.Second,
+		Sandbox:          sandbox,
+		History:          history,
+		CodingTests:      codingTests(),
+		TenantRuntimes:   map[int]string{3: "runsc"},
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+
+	// A candidate has no API key, only the test.
+	if _, err := svc.Execute(context.Background(), "print('hi')", "python", executor.WithCodingTest("test-1")); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	runs := sandbox.Runs()
+	if len(runs) != 1 || runs[0].Lang.Runtime != "runsc" {
+		t.Fatalf("expected the run under the runtime of company 3, got %+v", runs)
+	}
+	executions := history.recorded()
+	if len(executions) != 1 || executions[0].CompanyID == nil || *executions[0].CompanyID != 3 {
+		t.Errorf("expected the execution attributed to company 3, got %+v", executions)
+	}
+}
+
+func TestExecuteForProblemRecordsFailures(t *testing.T) {
+	history := &fakeHistory{}
+	goVersion := "1.21"
+	problem := &models.Problem{ID: 1, Title: "Echo", GoVersion: &goVersion}
+
+	for _, tc := range []struct {
+		name    string
+		problem *models.Problem
+		opts    []executor.Option
+	}{
+		{"MissingProblem", nil, nil},
+		{"PinnedVersion", problem, []executor.Option{executor.WithVersion("1.23")}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			history.executions = nil
+			svc := executor.NewService(executor.Config{
+				ExecutionTimeout: 10 * time.Second,
+				Sandbox:          &executor.FakeSandbox{Handler: echoSandbox},
+				History:          history,
+			}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, problemStub{tc.problem})
+
+			if _, err := svc.ExecuteForProblem(context.Background(), "package main", "go", 1, tc.opts...); err == nil {
+				t.Fatal("expected ExecuteForProblem to fail")
+			}
+			executions := history.recorded()
+			if len(executions) != 1 || executions[0].Error == "" || executions[0].ProblemID == nil || *executions[0].ProblemID != 1 {
+				t.Errorf("expected the failure to be recorded once for problem 1, got %+v", executions)
+			}
+		})
+	}
+}
+
+func TestExecuteRecordsLongLanguageAsValidUTF8(t *testing.T) {
+	history := &fakeHistory{}
+	svc := newRecordingService(&executor.FakeSandbox{Handler: echoSandbox}, history, nil)
+
+	language := strings.Repeat("a", 49) + "é"
+	if _, err := svc.Execute(context.Background(), "print('hi')", language); err == nil {
+		t.Fatal("expected an unsupported language to fail")
+	}
+	executions := history.recorded()
+	if len(executions) != 1 || executions[0].Language != strings.Repeat("a", 49) {
+		t.Errorf("expected the language cut before the split rune, got %+v", executions)
+	}
+}
+
+func TestExecuteForProblemRecordsOnce(t *testing.T) {
+	history := &fakeHistory{}
+	svc := executor.NewService(executor.Config{
+		ExecutionTimeout: 10 * time.Second,
+		Sandbox:          suiteSandbox(executor.FakeRun{Stdout: `{"Action":"pass","Test":"TestAdd"}`}),
+		History:          history,
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, problemStub{suiteProblem(nil)})
+
+	if _, err := svc.ExecuteForProblem(context.Background(), "package main", "go", 1); err != nil {
+		t.Fatalf("ExecuteForProblem failed: %v", err)
+	}
+	executions := history.recorded()
+	if len(executions) != 1 || executions[0].Verdict != models.VerdictAccepted || executions[0].Language != "go" {
+		t.Errorf("expected a single accepted execution, got %+v", executions)
+	}
+}
@@ -0,0 +1,136 @@
+package code_executor
+
+import (
+	"errors"
+	"testing"
+
+	executor "go-code-runner/internal/code_executor"
+)
+
+func TestDefaultRegistry(t *testing.T) {
+	registry := executor.DefaultRegistry()
+
+	t.Run("ShipsDefaultLanguages", func(t *testing.T) {
+		for _, name := range []string{"go", "python", "javascript", "cpp", "java"} {
+			lang, err := registry.Get(name)
+			if err != nil {
+				t.Fatalf("expected %s to be registered, got error: %v", name, err)
+			}
+			if lang.Image == "" || lang.SourceFile == "" || lang.RunCmd == "" {
+				t.Errorf("language %s is missing image, source file or run command", name)
+			}
+		}
+	})
+
+	t.Run("ResolvesAliases", func(t *testing.T) {
+		lang, err := registry.Get("JS")
+		if err != nil {
+			t.Fatalf("expected alias to resolve, got error: %v", err)
+		}
+		if lang.Name != "javascript" {
+			t.Errorf("expected javascript, got %s", lang.Name)
+		}
+	})
+
+	t.Run("RejectsUnknownLanguage", func(t *testing.T) {
+		_, err := registry.Get("cobol")
+		if !errors.Is(err, executor.ErrUnsupportedLanguage) {
+			t.Fatalf("expected ErrUnsupportedLanguage, got %v", err)
+		}
+	})
+
+	t.Run("ListIsSortedAndUnique", func(t *testing.T) {
+		languages := registry.List()
+		if len(languages) != 5 {
+			t.Fatalf("expected 5 languages, got %d", len(languages))
+		}
+		for i := 1; i < len(languages); i++ {
+			if languages[i-1].Name >= languages[i].Name {
+				t.Errorf("languages not sorted: %s before %s", languages[i-1].Name, languages[i].Name)
+			}
+		}
+	})
+}
+
+func TestLanguageWithVersion(t *testing.T) {
+	registry := executor.DefaultRegistry()
+	goLang, err := registry.Get("go")
+	if err != nil {
+		t.Fatalf("expected go to be registered, got error: %v", err)
+	}
+
+	cacheNames := func(lang *executor.Language) map[string]string {
+		names := make(map[string]string)
+		for _, cache := range lang.Caches {
+			names[cache.Target] = cache.Name
+		}
+		return names
+	}
+
+	t.Run("DefaultVersion", func(t *testing.T) {
+		lang, err := goLang.WithVersion("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if lang.DefaultVersion != "1.22" || lang.Image != "golang:1.22-alpine" {
+			t.Errorf("expected go 1.22 image, got %s (%s)", lang.Image, lang.DefaultVersion)
+		}
+	})
+
+	t.Run("PinnedVersionHasOwnBuildCache", func(t *testing.T) {
+		go121, err := goLang.WithVersion("1.21")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		go123, err := goLang.WithVersion("1.23")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if go121.Image != "golang:1.21-alpine" {
+			t.Errorf("expected golang:1.21-alpine, got %s", go121.Image)
+		}
+
+		caches121, caches123 := cacheNames(go121), cacheNames(go123)
+		if caches121["/cache/go-build"] == caches123["/cache/go-build"] {
+			t.Errorf("expected separate build caches, both use %s", caches121["/cache/go-build"])
+		}
+		if caches121["/go/pkg/mod"] != caches123["/go/pkg/mod"] {
+			t.Errorf("expected a shared module cache, got %s and %s", caches121["/go/pkg/mod"], caches123["/go/pkg/mod"])
+		}
+	})
+
+	t.Run("RejectsUnknownVersion", func(t *testing.T) {
+		if _, err := goLang.WithVersion("1.9"); !errors.Is(err, executor.ErrUnsupportedVersion) {
+			t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+		}
+
+		python, err := registry.Get("python")
+		if err != nil {
+			t.Fatalf("expected python to be registered, got error: %v", err)
+		}
+		if _, err := python.WithVersion("3.11"); !errors.Is(err, executor.ErrUnsupportedVersion) {
+			t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+		}
+	})
+
+	t.Run("SetToolchains", func(t *testing.T) {
+		registry := executor.DefaultRegistry()
+		err := registry.SetToolchains("go", []executor.Toolchain{
+			{Version: "1.23", Image: "golang:1.23-alpine"},
+			{Version: "1.24", Image: "golang:1.24-alpine"},
+		}, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		lang, _ := registry.Get("go")
+		if lang.DefaultVersion != "1.24" {
+			t.Errorf("expected default version 1.24, got %s", lang.DefaultVersion)
+		}
+
+		err = registry.SetToolchains("go", []executor.Toolchain{{Version: "1.23", Image: "golang:1.23-alpine"}}, "1.21")
+		if !errors.Is(err, executor.ErrUnsupportedVersion) {
+			t.Fatalf("expected ErrUnsupportedVersion for a missing default, got %v", err)
+		}
+	})
+}
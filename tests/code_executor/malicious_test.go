@@ -0,0 +1,192 @@
+package code_executor
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+	"go-code-runner/internal/platform/docker"
+)
+
+// maliciousPrograms try to take down the host rather than solve a problem.
+// Each must end in one of its verdicts within the execution timeout instead
+// of an internal error or a hang.
+var maliciousPrograms = []struct {
+	name     string
+	code     string
+	verdicts []models.Verdict
+	// contained marks programs that are only safe to run in a container, as
+	// the local sandbox does not cap the processes of the API's own user.
+	contained bool
+}{
+	{
+		name: "ForkBomb",
+		code: `import os
+while True:
+    try:
+        os.fork()
+    except OSError:
+        pass
+`,
+		verdicts:  []models.Verdict{models.VerdictTimeLimitExceeded, models.VerdictRuntimeError},
+		contained: true,
+	},
+	{
+		name: "DiskFill",
+		code: `chunk = b"0" * (1 << 20)
+with open("fill", "wb") as f:
+    while True:
+        f.write(chunk)
+`,
+		verdicts: []models.Verdict{models.VerdictRuntimeError},
+	},
+	{
+		name: "TmpFill",
+		code: `import tempfile
+chunk = b"0" * (1 << 20)
+with tempfile.TemporaryFile() as f:
+    while True:
+        f.write(chunk)
+`,
+		verdicts: []models.Verdict{models.VerdictRuntimeError},
+	},
+	{
+		name: "SleepForever",
+		code: `import time
+time.sleep(10 ** 6)
+`,
+		verdicts: []models.Verdict{models.VerdictTimeLimitExceeded},
+	},
+	{
+		name: "HugeStdout",
+		code: `import sys
+line = "x" * 1023 + "\n"
+while True:
+    sys.stdout.write(line)
+`,
+		verdicts: []models.Verdict{models.VerdictOutputLimitExceeded},
+	},
+	{
+		name: "HugeStderr",
+		code: `import sys
+while True:
+    print("x" * 1023, file=sys.stderr)
+`,
+		verdicts: []models.Verdict{models.VerdictOutputLimitExceeded},
+	},
+}
+
+func TestMaliciousProgramsLocal(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	sandbox, err := executor.NewLocalSandbox(executor.LocalSandboxConfig{MaxFileSizeMB: 8}, logger)
+	if err != nil {
+		t.Skipf("local sandbox unavailable: %v", err)
+	}
+	runMaliciousPrograms(t, sandbox, false)
+}
+
+func TestLocalSandboxWarnsWithoutProcessLimit(t *testing.T) {
+	var logs strings.Builder
+	if _, err := executor.NewLocalSandbox(executor.LocalSandboxConfig{MaxProcesses: 32}, log.New(&logs, "", 0)); err != nil {
+		t.Skipf("local sandbox unavailable: %v", err)
+	}
+	if !strings.Contains(logs.String(), "process limit of 32 is not enforced") {
+		t.Errorf("expected a warning about the process limit, got %q", logs.String())
+	}
+}
+
+// TestLocalSandboxProtectsWorkspace runs a program as a separate user that
+// tries to take over its own binary and to leave files behind.
+func TestLocalSandboxProtectsWorkspace(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("running as a separate user requires root")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	logger := log.New(io.Discard, "", 0)
+	sandbox, err := executor.NewLocalSandbox(executor.LocalSandboxConfig{UID: 65534, GID: 65534}, logger)
+	if err != nil {
+		t.Skipf("local sandbox unavailable: %v", err)
+	}
+	svc := executor.NewService(executor.Config{
+		ExecutionTimeout: time.Minute,
+		Sandbox:          sandbox,
+	}, executor.DefaultRegistry(), logger, nil, nil)
+
+	code := `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	err := os.Chmod("main", 0777)
+	fmt.Println(err != nil)
+	err = os.WriteFile("planted", nil, 0644)
+	fmt.Println(err != nil)
+	err = os.WriteFile(os.TempDir()+"/scratch", nil, 0644)
+	fmt.Println(err == nil)
+}
+`
+	result, err := svc.Execute(context.Background(), code, "go")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Verdict != models.VerdictAccepted || result.Output != "true\ntrue\ntrue\n" {
+		t.Errorf("expected a read-only workspace and a writable TMPDIR, got %s %q (%s%s)", result.Verdict, result.Output, result.CompileError, result.Error)
+	}
+}
+
+func TestMaliciousProgramsDocker(t *testing.T) {
+	client := docker.NewClient(os.Getenv("DOCKER_HOST"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx); err != nil {
+		t.Skipf("docker daemon unavailable: %v", err)
+	}
+
+	logger := log.New(io.Discard, "", 0)
+	runMaliciousPrograms(t, executor.NewDockerSandbox(client, executor.DockerSandboxConfig{}, logger), true)
+}
+
+func runMaliciousPrograms(t *testing.T, sandbox executor.Sandbox, contained bool) {
+	const timeout = 30 * time.Second
+	svc := executor.NewService(executor.Config{
+		ExecutionTimeout: timeout,
+		Sandbox:          sandbox,
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, nil)
+
+	for _, program := range maliciousPrograms {
+		t.Run(program.name, func(t *testing.T) {
+			if program.contained && !contained {
+				t.Skip("needs a container")
+			}
+
+			start := time.Now()
+			result, err := svc.Execute(context.Background(), program.code, "python",
+				executor.WithLimits(executor.Limits{TimeLimit: 500 * time.Millisecond, MemoryMB: 128}))
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > timeout {
+				t.Errorf("took %v, longer than the execution timeout", elapsed)
+			}
+
+			for _, verdict := range program.verdicts {
+				if result.Verdict == verdict {
+					return
+				}
+			}
+			t.Errorf("expected one of %v, got %s (%s)", program.verdicts, result.Verdict, result.Error)
+		})
+	}
+}
@@ -0,0 +1,591 @@
+package code_executor
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+	"go-code-runner/internal/platform/docker"
+)
+
+// fakeDaemon serves the Docker Engine API endpoints used by the sandbox and
+// answers every container with the same scripted outcome.
+type fakeDaemon struct {
+	stdout    string
+	stderr    string
+	exitCode  int
+	oomKilled bool
+	// stats is the output of the usage exec that follows every command.
+	stats string
+	// writes are created in the /app mount by every exec, owned by the
+	// sandbox user as build outputs would be.
+	writes []string
+	// barrier, if set, holds every exec until all it waits for have started.
+	barrier *sync.WaitGroup
+
+	mu      sync.Mutex
+	pulled  bool
+	created []docker.ContainerConfig
+	removed int
+	// execIn lists the container index every exec ran in, and sources
+	// whether main.py was present in its /app mount at that time.
+	execIn  []int
+	sources []bool
+	// modes records the permissions of the /app mount at every exec.
+	modes []fs.FileMode
+	// writable records whether the sandbox user could change anything in
+	// the /app mount at every exec.
+	writable []bool
+	// inputs records the stdin in the /input mount at every exec, and
+	// appFiles the files in the /app mount.
+	inputs   []string
+	appFiles [][]string
+}
+
+func (f *fakeDaemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+
+	f.mu.Lock()
+	barrier := f.barrier
+	f.mu.Unlock()
+	if barrier != nil && strings.HasPrefix(path, "/exec/exec") && strings.HasSuffix(path, "/start") {
+		barrier.Done()
+		barrier.Wait()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(path, "/images/") && strings.HasSuffix(path, "/json"):
+		if !f.pulled {
+			http.Error(w, `{"message":"No such image"}`, http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, `{}`)
+	case path == "/images/create":
+		f.pulled = true
+		io.WriteString(w, `{"status":"Pulling"}`+"\n"+`{"status":"Done"}`+"\n")
+	case path == "/containers/create":
+		var cfg docker.ContainerConfig
+		json.NewDecoder(r.Body).Decode(&cfg)
+		f.created = append(f.created, cfg)
+		fmt.Fprintf(w, `{"Id":"container-%d"}`, len(f.created)-1)
+	case strings.HasPrefix(path, "/containers/") && strings.HasSuffix(path, "/exec"):
+		var exec docker.ExecConfig
+		json.NewDecoder(r.Body).Decode(&exec)
+		if strings.Contains(strings.Join(exec.Cmd, " "), "/sys/fs/cgroup") {
+			io.WriteString(w, `{"Id":"usage"}`)
+			return
+		}
+		index, _ := strconv.Atoi(strings.TrimPrefix(strings.TrimSuffix(path, "/exec"), "/containers/container-"))
+		appDir, _, _ := strings.Cut(f.created[index].HostConfig.Binds[0], ":")
+		_, err := os.Stat(filepath.Join(appDir, "main.py"))
+		f.execIn = append(f.execIn, index)
+		f.sources = append(f.sources, err == nil)
+		var writable bool
+		filepath.WalkDir(appDir, func(p string, entry fs.DirEntry, err error) error {
+			if info, err := os.Lstat(p); err == nil && p != appDir {
+				sys, _ := info.Sys().(*syscall.Stat_t)
+				writable = writable || info.Mode().Perm()&0022 != 0 || (sys != nil && sys.Uid == 65534)
+			}
+			return nil
+		})
+		f.writable = append(f.writable, writable)
+		for _, name := range f.writes {
+			os.WriteFile(filepath.Join(appDir, name), []byte(name), 0666)
+			os.Chmod(filepath.Join(appDir, name), 0666)
+			os.Chown(filepath.Join(appDir, name), 65534, 65534)
+		}
+		if info, err := os.Stat(appDir); err == nil {
+			f.modes = append(f.modes, info.Mode().Perm())
+		}
+		var input []byte
+		for _, bind := range f.created[index].HostConfig.Binds {
+			if dir, ok := strings.CutSuffix(bind, ":/input:ro"); ok {
+				input, _ = os.ReadFile(filepath.Join(dir, "stdin"))
+			}
+		}
+		f.inputs = append(f.inputs, string(input))
+		var names []string
+		filepath.WalkDir(appDir, func(p string, _ fs.DirEntry, _ error) error {
+			names = append(names, strings.TrimPrefix(p, appDir))
+			return nil
+		})
+		f.appFiles = append(f.appFiles, names)
+		io.WriteString(w, `{"Id":"exec"}`)
+	case path == "/exec/usage/start":
+		writeFrame(w, 1, f.stats)
+	case strings.HasPrefix(path, "/exec/") && strings.HasSuffix(path, "/start"):
+		writeFrame(w, 1, f.stdout)
+		writeFrame(w, 2, f.stderr)
+	case strings.HasPrefix(path, "/exec/") && strings.HasSuffix(path, "/json"):
+		json.NewEncoder(w).Encode(map[string]any{"Running": false, "ExitCode": f.exitCode})
+	case strings.HasSuffix(path, "/start"):
+		w.WriteHeader(http.StatusNoContent)
+	case path == "/containers/json":
+		io.WriteString(w, `[]`)
+	case path == "/info":
+		io.WriteString(w, `{"Runtimes":{"runc":{"path":"runc"},"io.containerd.runc.v2":{}}}`)
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/json"):
+		json.NewEncoder(w).Encode(map[string]any{"State": map[string]any{"ExitCode": f.exitCode, "OOMKilled": f.oomKilled}})
+	case r.Method == http.MethodDelete:
+		f.removed++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeFrame(w io.Writer, stream byte, payload string) {
+	if payload == "" {
+		return
+	}
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	w.Write(header)
+	io.WriteString(w, payload)
+}
+
+func newDockerService(t *testing.T, daemon *fakeDaemon, cfg executor.DockerSandboxConfig) executor.Service {
+	t.Helper()
+	return newDockerServiceWithConfig(t, daemon, cfg, executor.Config{})
+}
+
+func newDockerServiceWithConfig(t *testing.T, daemon *fakeDaemon, cfg executor.DockerSandboxConfig, svcCfg executor.Config) executor.Service {
+	t.Helper()
+	logger := log.New(io.Discard, "", 0)
+	svcCfg.ExecutionTimeout = 10 * time.Second
+	svcCfg.Sandbox = executor.NewDockerSandbox(newDaemonClient(t, daemon), cfg, logger)
+	return executor.NewService(svcCfg, executor.DefaultRegistry(), logger, nil, nil)
+}
+
+func newDaemonClient(t *testing.T, daemon *fakeDaemon) *docker.Client {
+	t.Helper()
+	server := httptest.NewServer(daemon)
+	t.Cleanup(server.Close)
+	return docker.NewClient("tcp://" + strings.TrimPrefix(server.URL, "http://"))
+}
+
+func TestDockerSandboxRun(t *testing.T) {
+	daemon := &fakeDaemon{stdout: "hello\n", stderr: "warning\n"}
+	svc := newDockerService(t, daemon, executor.DockerSandboxConfig{})
+
+	result, err := svc.Execute(context.Background(), "print('hello')", "python", executor.WithLimits(executor.Limits{MemoryMB: 128, CPUs: 0.5}))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Verdict != models.VerdictAccepted {
+		t.Errorf("expected accepted, got %s", result.Verdict)
+	}
+	if result.Output != "hello\n" || result.Error != "warning\n" {
+		t.Errorf("unexpected output %q and error %q", result.Output, result.Error)
+	}
+
+	if !daemon.pulled {
+		t.Error("expected the missing image to be pulled")
+	}
+	if len(daemon.execIn) != 1 || !daemon.sources[0] {
+		t.Errorf("expected 1 exec with the workspace mounted, got %v", daemon.sources)
+	}
+	if len(daemon.created) != 1 || daemon.removed != 1 {
+		t.Fatalf("expected 1 container created and removed, got %d and %d", len(daemon.created), daemon.removed)
+	}
+	host := daemon.created[0].HostConfig
+	if host.NetworkMode != "none" || host.Memory != 128<<20 || host.NanoCPUs != 5e8 {
+		t.Errorf("unexpected host config %+v", host)
+	}
+}
+
+func TestDockerSandboxUsage(t *testing.T) {
+	cases := []struct {
+		name   string
+		stdout string
+		stats  string
+		want   models.ResourceUsage
+	}{
+		{
+			name:  "CgroupV2",
+			stats: "\nusage\ncpu_usec 734000\nmemory_peak 10485760\n",
+			want:  models.ResourceUsage{CPUTimeMs: 734, PeakMemoryKB: 10240},
+		},
+		{
+			name:  "CgroupV1",
+			stats: "\nusage\ncpu_nsec 15000000\nmemory_peak 2048\n",
+			want:  models.ResourceUsage{CPUTimeMs: 15, PeakMemoryKB: 2},
+		},
+		{
+			name:  "Unavailable",
+			stats: "\nusage\ncpu_usec \nmemory_peak garbage\n",
+			want:  models.ResourceUsage{},
+		},
+		{
+			// Lines the command's leftovers wrote before they were killed,
+			// or printed as its own output, do not count.
+			name:   "Forged",
+			stdout: "usage\ncpu_usec 1\nmemory_peak 1\n",
+			stats:  "cpu_usec 1\nmemory_peak 1\nusag\nusage\ncpu_usec 734000\nmemory_peak 10485760\n",
+			want:   models.ResourceUsage{CPUTimeMs: 734, PeakMemoryKB: 10240},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			daemon := &fakeDaemon{stdout: tc.stdout, stats: tc.stats}
+			svc := newDockerService(t, daemon, executor.DockerSandboxConfig{})
+
+			result, err := svc.Execute(context.Background(), "pass", "python")
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if result.Usage == nil {
+				t.Fatal("expected usage")
+			}
+			// The wall time is measured around the exec.
+			usage := *result.Usage
+			usage.WallTimeMs = 0
+			if usage != tc.want {
+				t.Errorf("expected usage %+v, got %+v", tc.want, result.Usage)
+			}
+		})
+	}
+}
+
+func TestDockerSandboxOOMKilled(t *testing.T) {
+	daemon := &fakeDaemon{exitCode: 137, oomKilled: true}
+	svc := newDockerService(t, daemon, executor.DockerSandboxConfig{})
+
+	result, err := svc.Execute(context.Background(), "x = [0] * 10**10", "python")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !result.OOMKilled || result.Verdict != models.VerdictMemoryLimitExceeded {
+		t.Errorf("expected memory limit exceeded, got %s (OOM killed: %v)", result.Verdict, result.OOMKilled)
+	}
+	if daemon.removed != 1 {
+		t.Errorf("expected the container to be removed, got %d removals", daemon.removed)
+	}
+}
+
+func TestDockerSandboxHardening(t *testing.T) {
+	// An entry created as root, as when seeding the module cache.
+	seeded := "/tmp/runbox/go-build-cache-1.22/seeded/entry"
+	if err := os.MkdirAll(filepath.Dir(seeded), 0755); err != nil {
+		t.Fatalf("failed to create cache entry: %v", err)
+	}
+	if err := os.WriteFile(seeded, nil, 0644); err != nil {
+		t.Fatalf("failed to create cache entry: %v", err)
+	}
+	os.Chown(filepath.Dir(seeded), 0, 0)
+	os.Chown(seeded, 0, 0)
+
+	daemon := &fakeDaemon{writes: []string{"main"}}
+	svc := newDockerService(t, daemon, executor.DockerSandboxConfig{})
+
+	code := "package main\n\nfunc main() {}\n"
+	result, err := svc.Execute(context.Background(), code, "go")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Verdict != models.VerdictAccepted {
+		t.Fatalf("expected accepted, got %s (%s)", result.Verdict, result.CompileError)
+	}
+
+	cfg := daemon.created[0]
+	if cfg.User != "65534:65534" {
+		t.Errorf("expected the container to run as nobody, got %q", cfg.User)
+	}
+	host := cfg.HostConfig
+	if !host.ReadonlyRootfs || host.Tmpfs["/tmp"] == "" {
+		t.Errorf("expected a read-only root with a /tmp tmpfs, got %v and %v", host.ReadonlyRootfs, host.Tmpfs)
+	}
+	if len(host.CapDrop) != 1 || host.CapDrop[0] != "ALL" {
+		t.Errorf("expected all capabilities dropped, got %v", host.CapDrop)
+	}
+	if host.PidsLimit != 256 {
+		t.Errorf("expected a pids limit of 256, got %d", host.PidsLimit)
+	}
+
+	var noNewPrivileges bool
+	var profile struct{ DefaultAction string }
+	for _, opt := range host.SecurityOpt {
+		if opt == "no-new-privileges" {
+			noNewPrivileges = true
+		}
+		if p, ok := strings.CutPrefix(opt, "seccomp="); ok {
+			if err := json.Unmarshal([]byte(p), &profile); err != nil {
+				t.Errorf("invalid seccomp profile: %v", err)
+			}
+		}
+	}
+	if !noNewPrivileges || profile.DefaultAction != "SCMP_ACT_ERRNO" {
+		t.Errorf("expected no-new-privileges and a deny-by-default seccomp profile, got %v", host.SecurityOpt)
+	}
+
+	ulimits := make(map[string]int64)
+	for _, u := range host.Ulimits {
+		ulimits[u.Name] = u.Hard
+	}
+	if ulimits["nofile"] == 0 || ulimits["fsize"] == 0 {
+		t.Errorf("expected nofile and fsize ulimits, got %v", host.Ulimits)
+	}
+
+	var gocache bool
+	for _, env := range cfg.Env {
+		gocache = gocache || env == "GOCACHE=/cache/go-build"
+	}
+	if !gocache {
+		t.Errorf("expected GOCACHE to point at the cache mount, got %v", cfg.Env)
+	}
+
+	// Only the compiler may write to the caches.
+	for i, mode := range []string{"rw", "ro"} {
+		for _, bind := range daemon.created[i].HostConfig.Binds[1:] {
+			if strings.Contains(bind, ":/input:") {
+				continue
+			}
+			if !strings.HasSuffix(bind, ":"+mode) {
+				t.Errorf("container %d: expected the cache mount %s to be %s", i, bind, mode)
+			}
+		}
+	}
+	if info, err := os.Stat("/tmp/runbox/go-build-cache-1.22"); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("expected a cache dir only its owner can write to, got %v (%v)", info, err)
+	}
+
+	for _, p := range []string{filepath.Dir(seeded), seeded} {
+		if info, err := os.Stat(p); err != nil || info.Sys().(*syscall.Stat_t).Uid != 65534 {
+			t.Errorf("expected %s to be handed to the sandbox user, got %v", p, err)
+		}
+	}
+
+	// The compiler may write to the workspace, the program may not, not
+	// even to the binary it was built into.
+	if len(daemon.modes) != 2 || daemon.modes[0] != 0777 || daemon.modes[1] != 0755 {
+		t.Errorf("expected a writable workspace for the compile only, got modes %v", daemon.modes)
+	}
+	if len(daemon.writable) != 2 || daemon.writable[1] {
+		t.Errorf("expected the build output to be taken back before the run, got %v", daemon.writable)
+	}
+}
+
+func TestDockerSandboxInputs(t *testing.T) {
+	for _, warm := range []int{0, 2} {
+		t.Run(fmt.Sprintf("WarmPool%d", warm), func(t *testing.T) {
+			daemon := &fakeDaemon{}
+			svc := newDockerServiceWithConfig(t, daemon, executor.DockerSandboxConfig{WarmPoolSize: warm},
+				executor.Config{MaxConcurrentSandboxes: 4, MaxParallelTestCases: 4})
+
+			testCases := []*models.TestCase{
+				{ID: 1, Input: "secret-1", ExpectedOutput: ""},
+				{ID: 2, Input: "secret-2", ExpectedOutput: ""},
+				{ID: 3, Input: "secret-3", ExpectedOutput: ""},
+			}
+			if _, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases); err != nil {
+				t.Fatalf("ExecuteWithTestCases failed: %v", err)
+			}
+
+			daemon.mu.Lock()
+			defer daemon.mu.Unlock()
+			seen := make(map[string]bool)
+			for i, input := range daemon.inputs {
+				seen[input] = true
+				for _, name := range daemon.appFiles[i] {
+					if strings.Contains(name, "input") || strings.Contains(name, "secret") {
+						t.Errorf("exec %d: expected no inputs in /app, found %s", i, name)
+					}
+				}
+			}
+			for _, tc := range testCases {
+				if !seen[tc.Input] {
+					t.Errorf("expected %s to be passed in the input mount, got %q", tc.Input, daemon.inputs)
+				}
+			}
+			for _, cfg := range daemon.created {
+				if !strings.HasSuffix(cfg.HostConfig.Binds[len(cfg.HostConfig.Binds)-1], ":/input:ro") {
+					t.Errorf("expected a read-only input mount, got %v", cfg.HostConfig.Binds)
+				}
+			}
+		})
+	}
+}
+
+func TestDockerSandboxTenantRuntime(t *testing.T) {
+	daemon := &fakeDaemon{}
+	svc := newDockerServiceWithConfig(t, daemon, executor.DockerSandboxConfig{}, executor.Config{
+		TenantRuntimes: map[int]string{7: "runsc"},
+	})
+
+	if _, err := svc.Execute(context.Background(), "pass", "python", executor.WithTenant(7)); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := svc.Execute(context.Background(), "pass", "python", executor.WithTenant(8)); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if len(daemon.created) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(daemon.created))
+	}
+	if runtime := daemon.created[0].HostConfig.Runtime; runtime != "runsc" {
+		t.Errorf("expected the tenant's runtime, got %q", runtime)
+	}
+	if runtime := daemon.created[1].HostConfig.Runtime; runtime != "" {
+		t.Errorf("expected the default runtime for other tenants, got %q", runtime)
+	}
+}
+
+func TestVerifyRuntimes(t *testing.T) {
+	client := newDaemonClient(t, &fakeDaemon{})
+
+	if err := executor.VerifyRuntimes(context.Background(), client, map[string][]string{"runc": {"language go"}}); err != nil {
+		t.Errorf("expected runc to be available, got %v", err)
+	}
+
+	err := executor.VerifyRuntimes(context.Background(), client, map[string][]string{"runsc": {"company 42"}})
+	if !errors.Is(err, executor.ErrRuntimeUnavailable) {
+		t.Fatalf("expected ErrRuntimeUnavailable, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "company 42") {
+		t.Errorf("expected the error to name the tenant, got %v", err)
+	}
+}
+
+func TestDockerSandboxWarmPool(t *testing.T) {
+	daemon := &fakeDaemon{stdout: "warm\n"}
+	svc := newDockerService(t, daemon, executor.DockerSandboxConfig{WarmPoolSize: 1})
+
+	// The first run prepares the language, which starts filling the pool.
+	if _, err := svc.Execute(context.Background(), "print('warm')", "python"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	waitFor(t, func() bool {
+		daemon.mu.Lock()
+		defer daemon.mu.Unlock()
+		for _, cfg := range daemon.created {
+			if strings.Contains(cfg.HostConfig.Binds[0], "/slots/") {
+				return true
+			}
+		}
+		return false
+	})
+
+	result, err := svc.Execute(context.Background(), "print('warm')", "python")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Output != "warm\n" {
+		t.Errorf("unexpected output %q", result.Output)
+	}
+
+	daemon.mu.Lock()
+	defer daemon.mu.Unlock()
+	last := daemon.execIn[len(daemon.execIn)-1]
+	if !strings.Contains(daemon.created[last].HostConfig.Binds[0], "/slots/") {
+		t.Errorf("expected the second run to use a warm container, got binds %v", daemon.created[last].HostConfig.Binds)
+	}
+	if !daemon.sources[len(daemon.sources)-1] {
+		t.Error("expected the workspace to be linked into the warm container")
+	}
+}
+
+// lockedWriter collects log output written from several goroutines.
+type lockedWriter struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.b.Write(p)
+}
+
+func (w *lockedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.b.String()
+}
+
+func TestDockerSandboxWarmPoolParallelTestCases(t *testing.T) {
+	// Many build outputs make parallel link-backs of the same file likely.
+	daemon := &fakeDaemon{stdout: "1\n"}
+	for i := 0; i < 2000; i++ {
+		daemon.writes = append(daemon.writes, fmt.Sprintf("out-%d.txt", i))
+	}
+	logs := &lockedWriter{}
+	logger := log.New(logs, "", 0)
+	const size = 4
+	svc := executor.NewService(executor.Config{
+		ExecutionTimeout:       10 * time.Second,
+		MaxConcurrentSandboxes: size,
+		MaxParallelTestCases:   size,
+		Sandbox:                executor.NewDockerSandbox(newDaemonClient(t, daemon), executor.DockerSandboxConfig{WarmPoolSize: size}, logger),
+	}, executor.DefaultRegistry(), logger, nil, nil)
+
+	// The first run prepares the language; wait for the pool to fill.
+	if _, err := svc.Execute(context.Background(), "print(1)", "python"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	waitFor(t, func() bool {
+		daemon.mu.Lock()
+		defer daemon.mu.Unlock()
+		warm := 0
+		for _, cfg := range daemon.created {
+			if strings.Contains(cfg.HostConfig.Binds[0], "/slots/") {
+				warm++
+			}
+		}
+		return warm >= size
+	})
+
+	// All test cases finish at once and link back into the workspace
+	// together.
+	daemon.mu.Lock()
+	daemon.barrier = &sync.WaitGroup{}
+	daemon.barrier.Add(size)
+	daemon.mu.Unlock()
+
+	testCases := make([]*models.TestCase, size)
+	for i := range testCases {
+		testCases[i] = &models.TestCase{ID: i + 1, Input: "1", ExpectedOutput: "1"}
+	}
+	results, err := svc.ExecuteWithTestCases(context.Background(), "print(input())", "python", testCases)
+	if err != nil {
+		t.Fatalf("ExecuteWithTestCases failed: %v", err)
+	}
+	if !results.Success {
+		t.Errorf("expected every test case to pass, got %+v", results.TestResults)
+	}
+	if strings.Contains(logs.String(), "Failed to link files back") {
+		t.Errorf("expected parallel runs to link back into the workspace cleanly:\n%s", logs.String())
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
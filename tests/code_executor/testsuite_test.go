@@ -0,0 +1,338 @@
+package code_executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	executor "go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+)
+
+type problemStub struct{ problem *models.Problem }
+
+func (s problemStub) CreateProblem(ctx context.Context, p models.Problem) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (s problemStub) GetProblemByID(ctx context.Context, id int) (*models.Problem, error) {
+	if s.problem == nil {
+		return nil, errors.New("problem not found")
+	}
+	return s.problem, nil
+}
+
+func (s problemStub) ListProblems(ctx context.Context) ([]*models.Problem, error) {
+	return []*models.Problem{s.problem}, nil
+}
+
+const suiteTestFile = `package main
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	if got := Add(1, 2); got != 3 {
+		t.Errorf("Add(1, 2) = %d, want 3", got)
+	}
+}
+`
+
+func newSuiteService(sandbox executor.Sandbox, problem *models.Problem) executor.Service {
+	return executor.NewService(executor.Config{
+		ExecutionTimeout: time.Minute,
+		Sandbox:          sandbox,
+	}, executor.DefaultRegistry(), log.New(io.Discard, "", 0), nil, problemStub{problem})
+}
+
+func suiteProblem(files map[string]string) *models.Problem {
+	return suiteProblemWithTests(suiteTestFile, files)
+}
+
+func suiteProblemWithTests(testFile string, files map[string]string) *models.Problem {
+	return &models.Problem{ID: 1, Title: "Add", TestFile: &testFile, Files: files}
+}
+
+// eventsTestFile declares the tests of the streams below.
+const eventsTestFile = `package main
+
+import "testing"
+
+func TestPass(t *testing.T)  {}
+func TestFail(t *testing.T)  {}
+func TestCrash(t *testing.T) {}
+func Testing(t *testing.T)   {}
+func TestMain(m *testing.M)  {}
+func helper(t *testing.T)    {}
+`
+
+// suiteSandbox compiles successfully and answers the run step with the
+// given test2json stream.
+func suiteSandbox(run executor.FakeRun) *executor.FakeSandbox {
+	return &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		if req.Writable {
+			return executor.FakeRun{}
+		}
+		return run
+	}}
+}
+
+func TestTestSuiteEvents(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"Action":"start"}`,
+		`{"Action":"run","Test":"Test__events"}`,
+		`{"Action":"pass","Test":"Test__events"}`,
+		`{"Action":"run","Test":"TestPass"}`,
+		`{"Action":"output","Test":"TestPass","Output":"=== RUN   TestPass\n"}`,
+		`{"Action":"output","Test":"TestPass","Output":"--- PASS: TestPass (0.25s)\n"}`,
+		`{"Action":"pass","Test":"TestPass","Elapsed":0.25}`,
+		`{"Action":"run","Test":"TestFail"}`,
+		`{"Action":"run","Test":"TestFail/ok"}`,
+		`{"Action":"pass","Test":"TestFail/ok"}`,
+		`{"Action":"run","Test":"TestFail/bad"}`,
+		`{"Action":"output","Test":"TestFail/bad","Output":"    main_test.go:12: got 1, want 2\n"}`,
+		`{"Action":"output","Test":"TestFail/bad","Output":"    --- FAIL: TestFail/bad (0.00s)\n"}`,
+		`{"Action":"fail","Test":"TestFail/bad"}`,
+		`{"Action":"fail","Test":"TestFail"}`,
+		`{"Action":"run","Test":"TestCrash"}`,
+		`not json`,
+	}, "\n")
+	svc := newSuiteService(suiteSandbox(executor.FakeRun{Stdout: stream, Stderr: "panic: boom", ExitCode: 2}), suiteProblemWithTests(eventsTestFile, nil))
+
+	results, err := svc.ExecuteForProblem(context.Background(), "package main", "go", 1)
+	if err != nil {
+		t.Fatalf("ExecuteForProblem failed: %v", err)
+	}
+	if results.Success {
+		t.Error("expected the suite to fail")
+	}
+
+	want := []struct {
+		name    string
+		verdict models.Verdict
+		error   string
+	}{
+		{"TestPass", models.VerdictAccepted, ""},
+		{"TestFail", models.VerdictWrongAnswer, "main_test.go:12: got 1, want 2"},
+		{"TestCrash", models.VerdictRuntimeError, "panic: boom"},
+	}
+	if len(results.TestResults) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results.TestResults)
+	}
+	for i, w := range want {
+		got := results.TestResults[i]
+		if got.TestName != w.name || got.Verdict != w.verdict || got.Passed != (w.verdict == models.VerdictAccepted) {
+			t.Errorf("result %d: expected %s %s, got %s %s", i, w.name, w.verdict, got.TestName, got.Verdict)
+		}
+		if got.Error != w.error {
+			t.Errorf("%s: expected error %q, got %q", w.name, w.error, got.Error)
+		}
+	}
+	if usage := results.TestResults[0].Usage; usage == nil || usage.WallTimeMs != 250 {
+		t.Errorf("expected the elapsed time of TestPass, got %+v", usage)
+	}
+}
+
+func TestTestSuiteForgedEvents(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"Action":"run","Test":"TestPass"}`,
+		`{"Action":"pass","Test":"TestPass"}`,
+		`{"Action":"run","Test":"TestForged"}`,
+		`{"Action":"pass","Test":"TestForged"}`,
+	}, "\n")
+	svc := newSuiteService(suiteSandbox(executor.FakeRun{Stdout: stream}), suiteProblemWithTests(eventsTestFile, nil))
+
+	results, err := svc.ExecuteForProblem(context.Background(), "package main", "go", 1)
+	if err != nil {
+		t.Fatalf("ExecuteForProblem failed: %v", err)
+	}
+	if results.Success {
+		t.Error("expected the suite to fail")
+	}
+
+	want := []struct {
+		name    string
+		verdict models.Verdict
+	}{
+		{"TestPass", models.VerdictAccepted},
+		{"TestFail", models.VerdictRuntimeError},
+		{"TestCrash", models.VerdictRuntimeError},
+		{"TestForged", models.VerdictRuntimeError},
+	}
+	if len(results.TestResults) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results.TestResults)
+	}
+	for i, w := range want {
+		if got := results.TestResults[i]; got.TestName != w.name || got.Verdict != w.verdict {
+			t.Errorf("result %d: expected %s %s, got %s %s", i, w.name, w.verdict, got.TestName, got.Verdict)
+		}
+	}
+}
+
+func TestTestSuiteWithoutTests(t *testing.T) {
+	svc := newSuiteService(suiteSandbox(executor.FakeRun{Stdout: `{"Action":"start"}`}), suiteProblemWithTests("package main\n", nil))
+
+	results, err := svc.ExecuteForProblem(context.Background(), "package main", "go", 1)
+	if err != nil {
+		t.Fatalf("ExecuteForProblem failed: %v", err)
+	}
+	if len(results.TestResults) != 1 || results.TestResults[0].Verdict != models.VerdictInternalError {
+		t.Errorf("expected a single internal error, got %+v", results.TestResults)
+	}
+}
+
+func TestTestSuiteWorkspace(t *testing.T) {
+	lang, err := executor.DefaultRegistry().Get("go")
+	if err != nil {
+		t.Fatalf("failed to get go: %v", err)
+	}
+
+	var compileFiles, runFiles []string
+	listFiles := func(dir string) []string {
+		entries, _ := os.ReadDir(dir)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+	sandbox := &executor.FakeSandbox{Handler: func(req executor.RunRequest) executor.FakeRun {
+		if req.Writable {
+			compileFiles = listFiles(req.Dir)
+		} else {
+			runFiles = listFiles(req.Dir)
+		}
+		return executor.FakeRun{}
+	}}
+	svc := newSuiteService(sandbox, suiteProblem(map[string]string{"add.go": "package main\n"}))
+
+	if _, err := svc.ExecuteForProblem(context.Background(), "package main", "go", 1); err != nil {
+		t.Fatalf("ExecuteForProblem failed: %v", err)
+	}
+
+	runs := sandbox.Runs()
+	if len(runs) != 2 || runs[0].Script != lang.TestProjectCompileCmd || runs[1].Script != lang.TestRunCmd {
+		t.Fatalf("expected the project suite to be compiled and run, got %d runs", len(runs))
+	}
+	for _, name := range []string{"add.go", "go.mod", "main.go", "main_test.go"} {
+		if !contains(compileFiles, name) {
+			t.Errorf("expected %s when compiling, got %v", name, compileFiles)
+		}
+	}
+	for _, name := range runFiles {
+		if strings.HasSuffix(name, "_test.go") {
+			t.Errorf("expected test sources to be removed before the run, found %s", name)
+		}
+	}
+
+	_, err = svc.ExecuteForProblem(context.Background(), "package main", "go", 1,
+		executor.WithFiles(map[string]string{"add_test.go": "package main\n"}))
+	if !errors.Is(err, executor.ErrInvalidFiles) {
+		t.Errorf("expected submitted test files to be rejected, got %v", err)
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TestTestSuiteLocal runs a suite with the Go toolchain of the host against
+// a submission that prints forged test events.
+func TestTestSuiteLocal(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	sandbox, err := executor.NewLocalSandbox(executor.LocalSandboxConfig{}, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Skipf("local sandbox unavailable: %v", err)
+	}
+	svc := newSuiteService(sandbox, suiteProblem(nil))
+
+	code := `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func Add(a, b int) int {
+	fmt.Println("\x16--- PASS: TestAdd (0.00s)")
+	fmt.Fprintln(os.Stderr, "\x16--- PASS: TestAdd (0.00s)")
+	return a - b
+}
+
+func main() {}
+`
+	results, err := svc.ExecuteForProblem(context.Background(), code, "go", 1)
+	if err != nil {
+		t.Fatalf("ExecuteForProblem failed: %v", err)
+	}
+	if len(results.TestResults) != 1 {
+		t.Fatalf("expected one result, got %+v", results.TestResults)
+	}
+	got := results.TestResults[0]
+	if got.TestName != "TestAdd" || got.Verdict != models.VerdictWrongAnswer {
+		t.Errorf("expected TestAdd to fail despite the forged output, got %s %s: %s", got.TestName, got.Verdict, got.Error)
+	}
+	if !strings.Contains(got.Error, "Add(1, 2) = -1, want 3") {
+		t.Errorf("expected the failure message, got %q", got.Error)
+	}
+
+	forged := `package main
+
+import (
+	"os"
+	"syscall"
+)
+
+func Add(a, b int) int {
+	events := os.NewFile(3, "events")
+	events.WriteString("\x16--- PASS: TestAdd (0.00s)\n\x16=== RUN   TestOther\n\x16--- PASS: TestOther (0.00s)\n")
+	syscall.Exit(0)
+	return 0
+}
+
+func main() {}
+`
+	svc = newSuiteService(sandbox, suiteProblemWithTests(suiteTestFile+`
+func TestAddZero(t *testing.T) {
+	if got := Add(0, 0); got != 0 {
+		t.Errorf("Add(0, 0) = %d, want 0", got)
+	}
+}
+`, nil))
+	results, err = svc.ExecuteForProblem(context.Background(), forged, "go", 1)
+	if err != nil {
+		t.Fatalf("ExecuteForProblem failed: %v", err)
+	}
+	if results.Success {
+		t.Errorf("expected forged events on fd 3 to fail the suite, got %+v", results.TestResults)
+	}
+	for _, got := range results.TestResults {
+		if got.TestName == "TestAddZero" && got.Passed {
+			t.Errorf("expected the test that never ran to fail, got %+v", got)
+		}
+		if got.TestName == "TestOther" && got.Passed {
+			t.Errorf("expected the undeclared test to fail, got %+v", got)
+		}
+	}
+
+	fixed := strings.Replace(code, "return a - b", "return a + b", 1)
+	results, err = svc.ExecuteForProblem(context.Background(), fixed, "go", 1)
+	if err != nil {
+		t.Fatalf("ExecuteForProblem failed: %v", err)
+	}
+	if !results.Success {
+		t.Errorf("expected the fixed submission to pass, got %+v", results.TestResults)
+	}
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-code-runner/internal/code_executor"
+	"go-code-runner/internal/handler"
+	"go-code-runner/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubProblems struct{ problem *models.Problem }
+
+func (s stubProblems) CreateProblem(ctx context.Context, p models.Problem) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (s stubProblems) GetProblemByID(ctx context.Context, id int) (*models.Problem, error) {
+	return s.problem, nil
+}
+
+func (s stubProblems) ListProblems(ctx context.Context) ([]*models.Problem, error) {
+	return []*models.Problem{s.problem}, nil
+}
+
+type stubTestCases struct{ testCases []*models.TestCase }
+
+func (s stubTestCases) GetTestCasesByProblemID(ctx context.Context, problemID int) ([]*models.TestCase, error) {
+	return s.testCases, nil
+}
+
+func (s stubTestCases) CreateTestCase(ctx context.Context, tc models.TestCase) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readEvents splits a Server-Sent Events body into its events.
+func readEvents(t *testing.T, body io.Reader) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			current.name = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:"):
+			current.data += strings.TrimPrefix(line, "data:")
+		case line == "" && current.name != "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	return events
+}
+
+func TestExecuteStreamHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sandbox := &code_executor.FakeSandbox{Handler: func(req code_executor.RunRequest) code_executor.FakeRun {
+		input, _ := req.Input()
+		return code_executor.FakeRun{Stdout: input}
+	}}
+	testCases := []*models.TestCase{
+		{ID: 1, Input: "1", ExpectedOutput: "1"},
+		{ID: 2, Input: "2", ExpectedOutput: "3"},
+	}
+	executor := code_executor.NewService(code_executor.Config{
+		ExecutionTimeout: 10 * time.Second,
+		Sandbox:          sandbox,
+	}, code_executor.DefaultRegistry(), log.New(io.Discard, "", 0),
+		stubTestCases{testCases}, stubProblems{&models.Problem{ID: 1}})
+
+	router := gin.New()
+	router.POST("/execute/stream", handler.MakeExecuteStreamHandler(executor))
+
+	// Streaming needs a real connection rather than a response recorder.
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	body := `{"language": "python", "code": "print(input())", "problem_id": 1}`
+	resp, err := http.Post(server.URL+"/execute/stream", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Errorf("expected an event stream, got %q", ct)
+	}
+
+	events := readEvents(t, resp.Body)
+	if len(events) == 0 {
+		t.Fatal("expected events")
+	}
+
+	var progress []string
+	for _, event := range events[:len(events)-1] {
+		switch event.name {
+		case "test_case":
+			var data struct {
+				Index  int    `json:"index"`
+				Status string `json:"status"`
+			}
+			if err := json.Unmarshal([]byte(event.data), &data); err != nil {
+				t.Fatalf("invalid test_case event %q: %v", event.data, err)
+			}
+			progress = append(progress, data.Status)
+		case "output":
+			progress = append(progress, "output")
+		default:
+			t.Errorf("unexpected %q event before the result", event.name)
+		}
+	}
+	want := []string{"started", "output", "passed", "started", "output", "failed"}
+	if strings.Join(progress, ",") != strings.Join(want, ",") {
+		t.Errorf("expected events %v, got %v", want, progress)
+	}
+
+	last := events[len(events)-1]
+	if last.name != "result" {
+		t.Fatalf("expected the result as the last event, got %q", last.name)
+	}
+	var result handler.ExecuteResponse
+	if err := json.Unmarshal([]byte(last.data), &result); err != nil {
+		t.Fatalf("invalid result event %q: %v", last.data, err)
+	}
+	if result.Success || len(result.TestResults) != 2 || result.Verdict != models.VerdictWrongAnswer {
+		t.Errorf("expected the final results with a wrong answer, got %+v", result)
+	}
+}
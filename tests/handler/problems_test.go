@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-code-runner/internal/handler"
+	"go-code-runner/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubProblemService struct{ problem *models.Problem }
+
+func (s stubProblemService) GetProblemByID(ctx context.Context, id int) (*models.Problem, error) {
+	return s.problem, nil
+}
+
+func (s stubProblemService) ListProblems(ctx context.Context) ([]*models.Problem, error) {
+	return []*models.Problem{s.problem}, nil
+}
+
+func (s stubProblemService) GetTestCasesByProblemID(ctx context.Context, problemID int) ([]*models.TestCase, error) {
+	return nil, nil
+}
+
+func TestProblemHandlersHideCheckerSource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testFile := "package main\n\nfunc TestSecret(t *testing.T) {}"
+	problem := &models.Problem{
+		ID:       1,
+		Title:    "Checked",
+		Checker:  &models.Checker{Type: models.CheckerCustom, Language: "python", Code: "print('secret checker')"},
+		TestFile: &testFile,
+	}
+	svc := stubProblemService{problem}
+
+	router := gin.New()
+	router.GET("/problems", handler.MakeListProblemsHandler(svc))
+	router.GET("/problems/:id", handler.MakeGetProblemHandler(svc))
+
+	for _, path := range []string{"/problems", "/problems/1"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+		body := rec.Body.String()
+		if strings.Contains(body, "secret") {
+			t.Errorf("%s: expected the checker and test file to stay hidden, got %s", path, body)
+		}
+		if !strings.Contains(body, `"type":"custom"`) {
+			t.Errorf("%s: expected the checker type, got %s", path, body)
+		}
+	}
+	if problem.Checker.Code == "" {
+		t.Error("expected the stored problem to keep its checker")
+	}
+}
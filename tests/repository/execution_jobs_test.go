@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"go-code-runner/internal/models"
+	"go-code-runner/internal/repository/execution_jobs"
+	"go-code-runner/tests/helpers"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestExecutionJobRepository(t *testing.T) {
+	db, cleanup := helpers.NewTestDB(t)
+	defer cleanup()
+
+	repo := execution_jobs.NewExecutionJobRepository(db)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	newJob := func() *models.ExecutionJob {
+		return &models.ExecutionJob{
+			ID:        uuid.New().String(),
+			Language:  "go",
+			Code:      "package main\nfunc main() {}",
+			Status:    models.JobStatusQueued,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	t.Run("CreateAndGetJob", func(t *testing.T) {
+		job := newJob()
+		if err := repo.CreateJob(context.Background(), job); err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+
+		got, err := repo.GetJobByID(context.Background(), job.ID)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+
+		if got.Status != models.JobStatusQueued {
+			t.Errorf("expected status %s, got %s", models.JobStatusQueued, got.Status)
+		}
+		if got.Code != job.Code {
+			t.Errorf("expected code %q, got %q", job.Code, got.Code)
+		}
+		if got.ProblemID != nil {
+			t.Errorf("expected no problem ID, got %d", *got.ProblemID)
+		}
+		if len(got.TestResults) != 0 {
+			t.Errorf("expected no test results, got %d", len(got.TestResults))
+		}
+	})
+
+	t.Run("UpdateJobStoresPartialResults", func(t *testing.T) {
+		job := newJob()
+		if err := repo.CreateJob(context.Background(), job); err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+
+		startedAt := time.Now().UTC().Truncate(time.Microsecond)
+		job.Status = models.JobStatusRunning
+		job.StartedAt = &startedAt
+		job.TestResults = []models.TestResult{
+			{TestCaseID: 1, ActualOutput: "3", Passed: true},
+		}
+		if err := repo.UpdateJob(context.Background(), job); err != nil {
+			t.Fatalf("failed to update job: %v", err)
+		}
+
+		got, err := repo.GetJobByID(context.Background(), job.ID)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+
+		if got.Status != models.JobStatusRunning {
+			t.Errorf("expected status %s, got %s", models.JobStatusRunning, got.Status)
+		}
+		if got.StartedAt == nil || !got.StartedAt.Equal(startedAt) {
+			t.Errorf("expected started_at %v, got %v", startedAt, got.StartedAt)
+		}
+		if len(got.TestResults) != 1 || !got.TestResults[0].Passed {
+			t.Errorf("expected one passed test result, got %+v", got.TestResults)
+		}
+	})
+
+	t.Run("CancelledJobIsFinal", func(t *testing.T) {
+		job := newJob()
+		if err := repo.CreateJob(context.Background(), job); err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+
+		job.Status = models.JobStatusCancelled
+		if err := repo.UpdateJob(context.Background(), job); err != nil {
+			t.Fatalf("failed to cancel job: %v", err)
+		}
+
+		job.Status = models.JobStatusRunning
+		if err := repo.UpdateJob(context.Background(), job); err != nil {
+			t.Fatalf("failed to update job: %v", err)
+		}
+
+		got, err := repo.GetJobByID(context.Background(), job.ID)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		if got.Status != models.JobStatusCancelled {
+			t.Errorf("expected cancelled job to stay cancelled, got %s", got.Status)
+		}
+	})
+
+	t.Run("CancelJobSkipsFinishedJobs", func(t *testing.T) {
+		queued := newJob()
+		finished := newJob()
+		finished.Status = models.JobStatusFinished
+		for _, job := range []*models.ExecutionJob{queued, finished} {
+			if err := repo.CreateJob(context.Background(), job); err != nil {
+				t.Fatalf("failed to create job: %v", err)
+			}
+		}
+
+		for job, want := range map[*models.ExecutionJob]bool{queued: true, finished: false} {
+			cancelled, err := repo.CancelJob(context.Background(), job.ID, time.Now())
+			if err != nil {
+				t.Fatalf("failed to cancel job: %v", err)
+			}
+			if cancelled != want {
+				t.Errorf("job with status %s: expected cancelled %v, got %v", job.Status, want, cancelled)
+			}
+		}
+
+		got, err := repo.GetJobByID(context.Background(), finished.ID)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		if got.Status != models.JobStatusFinished {
+			t.Errorf("expected the finished job to stay finished, got %s", got.Status)
+		}
+	})
+
+	t.Run("FailUnfinishedJobs", func(t *testing.T) {
+		running := newJob()
+		running.Status = models.JobStatusRunning
+		finished := newJob()
+		finished.Status = models.JobStatusFinished
+		for _, job := range []*models.ExecutionJob{running, finished} {
+			if err := repo.CreateJob(context.Background(), job); err != nil {
+				t.Fatalf("failed to create job: %v", err)
+			}
+		}
+
+		n, err := repo.FailUnfinishedJobs(context.Background(), "interrupted")
+		if err != nil {
+			t.Fatalf("failed to fail unfinished jobs: %v", err)
+		}
+		if n < 1 {
+			t.Errorf("expected at least the running job to fail, got %d", n)
+		}
+
+		got, err := repo.GetJobByID(context.Background(), running.ID)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		if got.Status != models.JobStatusFailed || got.Error != "interrupted" || got.FinishedAt == nil {
+			t.Errorf("expected the running job to fail, got %+v", got)
+		}
+		got, err = repo.GetJobByID(context.Background(), finished.ID)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		if got.Status != models.JobStatusFinished {
+			t.Errorf("expected the finished job to stay finished, got %s", got.Status)
+		}
+	})
+}
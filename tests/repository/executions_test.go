@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go-code-runner/internal/models"
+	"go-code-runner/internal/repository/coding_test"
+	"go-code-runner/internal/repository/company"
+	"go-code-runner/internal/repository/executions"
+	"go-code-runner/internal/repository/problems"
+	"go-code-runner/tests/helpers"
+	"testing"
+	"time"
+)
+
+func TestExecutionRepository(t *testing.T) {
+	db, cleanup := helpers.NewTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	testCompany, err := company.New(db).Create(ctx, &models.Company{
+		Name:         "Execution Company",
+		Email:        fmt.Sprintf("executions-%d@example.com", time.Now().UnixNano()),
+		PasswordHash: "password_hash",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test company: %v", err)
+	}
+	problemID, err := problems.NewProblemRepository(db).CreateProblem(ctx, models.Problem{
+		Title:       "Execution Problem",
+		Description: "This is a test problem",
+		Difficulty:  "Easy",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test problem: %v", err)
+	}
+	test := &models.CodingTest{
+		ID:                  fmt.Sprintf("test-executions-%d", time.Now().UnixNano()),
+		CompanyID:           testCompany.ID,
+		ProblemID:           problemID,
+		Status:              models.TestStatusPending,
+		ExpiresAt:           time.Now().Add(24 * time.Hour),
+		TestDurationMinutes: 60,
+	}
+	if err := coding_test.New(db).CreateTest(ctx, test); err != nil {
+		t.Fatalf("failed to create test coding test: %v", err)
+	}
+
+	repo := executions.NewExecutionRepository(db)
+	started := time.Now().UTC().Truncate(time.Microsecond)
+	create := func(t *testing.T, execution *models.Execution) *models.Execution {
+		t.Helper()
+		execution.CodeHash = "hash"
+		execution.Language = "go"
+		execution.FinishedAt = execution.StartedAt.Add(time.Second)
+		execution.DurationMs = 1000
+		if err := repo.CreateExecution(ctx, execution); err != nil {
+			t.Fatalf("failed to create execution: %v", err)
+		}
+		if execution.ID == 0 || execution.CreatedAt.IsZero() {
+			t.Fatalf("expected the ID and creation time to be set, got %+v", execution)
+		}
+		return execution
+	}
+
+	byKey := create(t, &models.Execution{CompanyID: &testCompany.ID, Verdict: models.VerdictAccepted, StartedAt: started})
+	byTest := create(t, &models.Execution{
+		TestID:    &test.ID,
+		ProblemID: &problemID,
+		Verdict:   models.VerdictWrongAnswer,
+		TestCases: []models.ExecutionTestCase{{TestCaseID: 1, Verdict: models.VerdictWrongAnswer}},
+		StartedAt: started.Add(time.Minute),
+	})
+	create(t, &models.Execution{Verdict: models.VerdictAccepted, StartedAt: started})
+
+	t.Run("ListExecutionsByCompany", func(t *testing.T) {
+		list, total, err := repo.ListExecutionsByCompany(ctx, testCompany.ID, 10, 0)
+		if err != nil {
+			t.Fatalf("failed to list executions: %v", err)
+		}
+		if total != 2 || len(list) != 2 {
+			t.Fatalf("expected the key's and the test's executions, got %d of %d", len(list), total)
+		}
+		if list[0].ID != byTest.ID || list[1].ID != byKey.ID {
+			t.Errorf("expected the newest execution first, got %d and %d", list[0].ID, list[1].ID)
+		}
+		if len(list[0].TestCases) != 1 || list[0].TestCases[0].Verdict != models.VerdictWrongAnswer {
+			t.Errorf("expected the test case verdicts, got %+v", list[0].TestCases)
+		}
+		if !list[1].StartedAt.Equal(started) || list[1].DurationMs != 1000 {
+			t.Errorf("expected the stored timings, got %+v", list[1])
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		list, total, err := repo.ListExecutionsByCompany(ctx, testCompany.ID, 1, 1)
+		if err != nil {
+			t.Fatalf("failed to list executions: %v", err)
+		}
+		if total != 2 || len(list) != 1 || list[0].ID != byKey.ID {
+			t.Errorf("expected the second page to hold the oldest execution, got %d of %d", len(list), total)
+		}
+	})
+
+	t.Run("ListExecutionsByTest", func(t *testing.T) {
+		list, total, err := repo.ListExecutionsByTest(ctx, test.ID, 10, 0)
+		if err != nil {
+			t.Fatalf("failed to list executions: %v", err)
+		}
+		if total != 1 || len(list) != 1 || list[0].ID != byTest.ID {
+			t.Fatalf("expected the test's execution, got %d of %d", len(list), total)
+		}
+		if list[0].ProblemID == nil || *list[0].ProblemID != problemID || list[0].CompanyID != nil {
+			t.Errorf("expected the attribution to be kept, got %+v", list[0])
+		}
+	})
+}
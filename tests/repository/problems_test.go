@@ -50,6 +50,138 @@ func TestProblemRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("CreateProblemWithLimits", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		timeLimitMs, memoryLimitMB, cpuQuota := 1500, 128, 0.25
+		goVersion := "1.21"
+		problem := models.Problem{
+			Title:         "Limited Problem",
+			Description:   "This problem has its own limits",
+			Difficulty:    "Hard",
+			TimeLimitMs:   &timeLimitMs,
+			MemoryLimitMB: &memoryLimitMB,
+			CPUQuota:      &cpuQuota,
+			GoVersion:     &goVersion,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+
+		id, err := repo.CreateProblem(context.Background(), problem)
+		if err != nil {
+			t.Fatalf("failed to create problem: %v", err)
+		}
+
+		createdProblem, err := repo.GetProblemByID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("failed to get created problem: %v", err)
+		}
+
+		if createdProblem.TimeLimitMs == nil || *createdProblem.TimeLimitMs != timeLimitMs {
+			t.Errorf("expected time limit %d, got %v", timeLimitMs, createdProblem.TimeLimitMs)
+		}
+		if createdProblem.MemoryLimitMB == nil || *createdProblem.MemoryLimitMB != memoryLimitMB {
+			t.Errorf("expected memory limit %d, got %v", memoryLimitMB, createdProblem.MemoryLimitMB)
+		}
+		if createdProblem.CPUQuota == nil || *createdProblem.CPUQuota != cpuQuota {
+			t.Errorf("expected cpu quota %v, got %v", cpuQuota, createdProblem.CPUQuota)
+		}
+		if createdProblem.GoVersion == nil || *createdProblem.GoVersion != goVersion {
+			t.Errorf("expected go version %s, got %v", goVersion, createdProblem.GoVersion)
+		}
+	})
+
+	t.Run("CreateProblemWithChecker", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		problem := models.Problem{
+			Title:       "Floating Point Problem",
+			Description: "This problem accepts answers within an epsilon",
+			Difficulty:  "Medium",
+			Checker:     &models.Checker{Type: models.CheckerFloat, AbsEpsilon: 1e-6},
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+
+		id, err := repo.CreateProblem(context.Background(), problem)
+		if err != nil {
+			t.Fatalf("failed to create problem: %v", err)
+		}
+
+		createdProblem, err := repo.GetProblemByID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("failed to get created problem: %v", err)
+		}
+
+		if createdProblem.Checker == nil {
+			t.Fatal("expected checker to be stored, got nil")
+		}
+		if *createdProblem.Checker != *problem.Checker {
+			t.Errorf("expected checker %+v, got %+v", *problem.Checker, *createdProblem.Checker)
+		}
+	})
+
+	t.Run("CreateProblemWithSignature", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		problem := models.Problem{
+			Title:       "Function Problem",
+			Description: "Candidates implement a single function",
+			Difficulty:  "Easy",
+			Signature: &models.FunctionSignature{
+				Name:       "twoSum",
+				Params:     []models.FunctionParam{{Name: "nums", Type: "int[]"}, {Name: "target", Type: "int"}},
+				ReturnType: "int[]",
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		id, err := repo.CreateProblem(context.Background(), problem)
+		if err != nil {
+			t.Fatalf("failed to create problem: %v", err)
+		}
+
+		createdProblem, err := repo.GetProblemByID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("failed to get created problem: %v", err)
+		}
+
+		if createdProblem.Signature == nil {
+			t.Fatal("expected signature to be stored, got nil")
+		}
+		if createdProblem.Signature.Name != "twoSum" || len(createdProblem.Signature.Params) != 2 {
+			t.Errorf("unexpected signature %+v", *createdProblem.Signature)
+		}
+		if createdProblem.Checker != nil {
+			t.Errorf("expected no checker, got %+v", *createdProblem.Checker)
+		}
+	})
+
+	t.Run("CreateProblemWithTestFile", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		testFile := "package main\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {}\n"
+		problem := models.Problem{
+			Title:       "Test Suite Problem",
+			Description: "This problem is graded by go test",
+			Difficulty:  "Medium",
+			TestFile:    &testFile,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+
+		id, err := repo.CreateProblem(context.Background(), problem)
+		if err != nil {
+			t.Fatalf("failed to create problem: %v", err)
+		}
+
+		createdProblem, err := repo.GetProblemByID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("failed to get created problem: %v", err)
+		}
+
+		if createdProblem.TestFile == nil || *createdProblem.TestFile != testFile {
+			t.Errorf("expected test file %q, got %v", testFile, createdProblem.TestFile)
+		}
+	})
+
 	t.Run("GetProblemByID", func(t *testing.T) {
 		now := time.Now().UTC().Truncate(time.Microsecond)
 		problem := models.Problem{
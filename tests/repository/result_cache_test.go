@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"go-code-runner/internal/models"
+	"go-code-runner/internal/repository/result_cache"
+	"go-code-runner/tests/helpers"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestResultCacheRepository(t *testing.T) {
+	db, cleanup := helpers.NewTestDB(t)
+	defer cleanup()
+
+	repo := result_cache.NewResultCacheRepository(db)
+	ctx := context.Background()
+	results := &models.ExecutionResults{
+		Success:     true,
+		Verdict:     models.VerdictAccepted,
+		TestResults: []models.TestResult{{TestCaseID: 1, ActualOutput: "42", Passed: true, Verdict: models.VerdictAccepted}},
+	}
+
+	t.Run("PutAndGet", func(t *testing.T) {
+		key := uuid.New().String()
+		if err := repo.PutCachedResult(ctx, key, results, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("failed to store result: %v", err)
+		}
+
+		got, _, err := repo.GetCachedResult(ctx, key)
+		if err != nil {
+			t.Fatalf("failed to get result: %v", err)
+		}
+		if got == nil || len(got.TestResults) != 1 || got.TestResults[0].ActualOutput != "42" {
+			t.Errorf("expected the stored results, got %+v", got)
+		}
+	})
+
+	t.Run("ExpiredIsMissing", func(t *testing.T) {
+		key := uuid.New().String()
+		if err := repo.PutCachedResult(ctx, key, results, time.Now().Add(-time.Minute)); err != nil {
+			t.Fatalf("failed to store result: %v", err)
+		}
+
+		got, _, err := repo.GetCachedResult(ctx, key)
+		if err != nil {
+			t.Fatalf("failed to get result: %v", err)
+		}
+		if got != nil {
+			t.Errorf("expected no results for an expired entry, got %+v", got)
+		}
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			if err := repo.PutCachedResult(ctx, uuid.New().String(), results, time.Now().Add(time.Hour)); err != nil {
+				t.Fatalf("failed to store result: %v", err)
+			}
+		}
+		if err := repo.PruneCachedResults(ctx, 1); err != nil {
+			t.Fatalf("failed to prune results: %v", err)
+		}
+
+		var count int
+		if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM execution_result_cache`).Scan(&count); err != nil {
+			t.Fatalf("failed to count results: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected 1 result after pruning, got %d", count)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package coding_test
 import (
 	"context"
 	"errors"
+	"go-code-runner/internal/code_executor"
 	"go-code-runner/internal/models"
 	svc "go-code-runner/internal/service/coding_test"
 	"testing"
@@ -172,13 +173,23 @@ func (m *mockCompanyRepository) UpdateClientID(ctx context.Context, id int, clie
 	return nil
 }
 
+// mockAnalyzer reports one finding for Go code and supports nothing else.
+type mockAnalyzer struct{}
+
+func (m *mockAnalyzer) Analyze(ctx context.Context, code string, language string, opts ...code_executor.Option) ([]models.Diagnostic, error) {
+	if language != "go" {
+		return nil, code_executor.ErrAnalysisUnsupported
+	}
+	return []models.Diagnostic{{File: "main.go", Line: 3, Severity: "warning", Message: "unreachable code", Source: "vet"}}, nil
+}
+
 func TestGenerateTest(t *testing.T) {
 	codingTestRepo := newMockCodingTestRepository()
 	problemRepo := newMockProblemRepository()
 	companyRepo := newMockCompanyRepository()
 	baseURL := "http://example.com"
 
-	service := svc.New(codingTestRepo, problemRepo, companyRepo, baseURL)
+	service := svc.New(codingTestRepo, problemRepo, companyRepo, nil, baseURL)
 
 	t.Run("SuccessfulGeneration", func(t *testing.T) {
 		companyID := 1
@@ -236,7 +247,7 @@ func TestVerifyTest(t *testing.T) {
 	companyRepo := newMockCompanyRepository()
 	baseURL := "http://example.com"
 
-	service := svc.New(codingTestRepo, problemRepo, companyRepo, baseURL)
+	service := svc.New(codingTestRepo, problemRepo, companyRepo, nil, baseURL)
 
 	testID := "test-verify"
 	test := &models.CodingTest{
@@ -305,7 +316,7 @@ func TestStartTest(t *testing.T) {
 	companyRepo := newMockCompanyRepository()
 	baseURL := "http://example.com"
 
-	service := svc.New(codingTestRepo, problemRepo, companyRepo, baseURL)
+	service := svc.New(codingTestRepo, problemRepo, companyRepo, nil, baseURL)
 
 	testID := "test-start"
 	test := &models.CodingTest{
@@ -387,7 +398,7 @@ func TestSubmitTest(t *testing.T) {
 	companyRepo := newMockCompanyRepository()
 	baseURL := "http://example.com"
 
-	service := svc.New(codingTestRepo, problemRepo, companyRepo, baseURL)
+	service := svc.New(codingTestRepo, problemRepo, companyRepo, &mockAnalyzer{}, baseURL)
 
 	testID := "test-submit"
 	now := time.Now()
@@ -415,7 +426,7 @@ func TestSubmitTest(t *testing.T) {
 		code := "console.log('Hello, World!');"
 		passedPercentage := 80
 
-		err := service.SubmitTest(context.Background(), testID, code, passedPercentage)
+		err := service.SubmitTest(context.Background(), testID, code, "javascript", passedPercentage)
 		if err != nil {
 			t.Fatalf("failed to submit test: %v", err)
 		}
@@ -437,6 +448,39 @@ func TestSubmitTest(t *testing.T) {
 		if *submittedTest.PassedPercentage != passedPercentage {
 			t.Errorf("expected PassedPercentage %d, got %d", passedPercentage, *submittedTest.PassedPercentage)
 		}
+		if submittedTest.CodeAnalysis != nil {
+			t.Errorf("expected no code analysis for an unsupported language, got %+v", submittedTest.CodeAnalysis)
+		}
+	})
+
+	t.Run("SubmissionWithCodeAnalysis", func(t *testing.T) {
+		analyzedTestID := "test-submit-analyzed"
+		startedTime := time.Now()
+		err := codingTestRepo.CreateTest(context.Background(), &models.CodingTest{
+			ID:                  analyzedTestID,
+			CompanyID:           1,
+			ProblemID:           1,
+			Status:              models.TestStatusStarted,
+			StartedAt:           &startedTime,
+			ExpiresAt:           time.Now().Add(24 * time.Hour),
+			TestDurationMinutes: 60,
+		})
+		if err != nil {
+			t.Fatalf("failed to create test for submission: %v", err)
+		}
+
+		err = service.SubmitTest(context.Background(), analyzedTestID, "package main", "go", 100)
+		if err != nil {
+			t.Fatalf("failed to submit test: %v", err)
+		}
+
+		submittedTest, err := codingTestRepo.GetTestByID(context.Background(), analyzedTestID)
+		if err != nil {
+			t.Fatalf("failed to get submitted test: %v", err)
+		}
+		if len(submittedTest.CodeAnalysis) != 1 || submittedTest.CodeAnalysis[0].Source != "vet" {
+			t.Errorf("expected the vet finding to be stored, got %+v", submittedTest.CodeAnalysis)
+		}
 	})
 
 	t.Run("TestNotInProgress", func(t *testing.T) {
@@ -456,7 +500,7 @@ func TestSubmitTest(t *testing.T) {
 			t.Fatalf("failed to create pending test: %v", err)
 		}
 
-		err = service.SubmitTest(context.Background(), pendingTestID, "code", 50)
+		err = service.SubmitTest(context.Background(), pendingTestID, "code", "go", 50)
 		if err == nil {
 			t.Error("expected error when test not in progress, got nil")
 		}
@@ -486,7 +530,7 @@ func TestSubmitTest(t *testing.T) {
 			t.Fatalf("failed to create expired test: %v", err)
 		}
 
-		err = service.SubmitTest(context.Background(), expiredTestID, "code", 50)
+		err = service.SubmitTest(context.Background(), expiredTestID, "code", "go", 50)
 		if err == nil {
 			t.Error("expected error when test expired, got nil")
 		}
@@ -499,7 +543,7 @@ func TestGetCompanyTests(t *testing.T) {
 	companyRepo := newMockCompanyRepository()
 	baseURL := "http://example.com"
 
-	service := svc.New(codingTestRepo, problemRepo, companyRepo, baseURL)
+	service := svc.New(codingTestRepo, problemRepo, companyRepo, nil, baseURL)
 
 	companyID := 1
 	for i := 0; i < 3; i++ {
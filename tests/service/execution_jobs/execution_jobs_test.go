@@ -0,0 +1,365 @@
+package execution_jobs
+
+import (
+	"context"
+	"errors"
+	"go-code-runner/internal/code_executor"
+	"go-code-runner/internal/models"
+	svc "go-code-runner/internal/service/execution_jobs"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type mockJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]models.ExecutionJob
+}
+
+func newMockJobRepository() *mockJobRepository {
+	return &mockJobRepository{jobs: make(map[string]models.ExecutionJob)}
+}
+
+func (m *mockJobRepository) CreateJob(ctx context.Context, job *models.ExecutionJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockJobRepository) GetJobByID(ctx context.Context, id string) (*models.ExecutionJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &job, nil
+}
+
+func (m *mockJobRepository) UpdateJob(ctx context.Context, job *models.ExecutionJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.jobs[job.ID].Status == models.JobStatusCancelled {
+		return nil
+	}
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockJobRepository) FailUnfinishedJobs(ctx context.Context, message string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for id, job := range m.jobs {
+		if job.Status == models.JobStatusQueued || job.Status == models.JobStatusRunning {
+			job.Status = models.JobStatusFailed
+			job.Error = message
+			m.jobs[id] = job
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockJobRepository) CancelJob(ctx context.Context, id string, finishedAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok || (job.Status != models.JobStatusQueued && job.Status != models.JobStatusRunning) {
+		return false, nil
+	}
+	job.Status = models.JobStatusCancelled
+	job.FinishedAt = &finishedAt
+	m.jobs[id] = job
+	return true, nil
+}
+
+// staleJobRepository reads jobs as still running, like a Cancel racing with
+// the end of the job.
+type staleJobRepository struct {
+	*mockJobRepository
+}
+
+func (m staleJobRepository) GetJobByID(ctx context.Context, id string) (*models.ExecutionJob, error) {
+	job, err := m.mockJobRepository.GetJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job.Status = models.JobStatusRunning
+	return job, nil
+}
+
+type mockExecutor struct {
+	block     bool
+	cancelled chan struct{}
+
+	mu    sync.Mutex
+	opts  int
+	calls int
+}
+
+// executions returns how many executions were started.
+func (m *mockExecutor) executions() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// receivedOptions returns how many options the last execution got.
+func (m *mockExecutor) receivedOptions() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.opts
+}
+
+func (m *mockExecutor) Languages() []code_executor.Language {
+	return code_executor.DefaultRegistry().List()
+}
+
+func (m *mockExecutor) Language(name string) (*code_executor.Language, error) {
+	return code_executor.DefaultRegistry().Get(name)
+}
+
+func (m *mockExecutor) Execute(ctx context.Context, code string, language string, opts ...code_executor.Option) (*code_executor.ExecutionResult, error) {
+	m.mu.Lock()
+	m.opts = len(opts)
+	m.calls++
+	m.mu.Unlock()
+	return &code_executor.ExecutionResult{Output: "hello\n"}, nil
+}
+
+func (m *mockExecutor) ExecuteWithTestCases(ctx context.Context, code string, language string, testCases []*models.TestCase, opts ...code_executor.Option) (*models.ExecutionResults, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockExecutor) Analyze(ctx context.Context, code string, language string, opts ...code_executor.Option) ([]models.Diagnostic, error) {
+	return []models.Diagnostic{}, nil
+}
+
+func (m *mockExecutor) Format(ctx context.Context, code string, language string, opts ...code_executor.Option) (*code_executor.FormatResult, error) {
+	return &code_executor.FormatResult{Code: code}, nil
+}
+
+func (m *mockExecutor) ExecuteForProblem(ctx context.Context, code string, language string, problemID int, opts ...code_executor.Option) (*models.ExecutionResults, error) {
+	m.mu.Lock()
+	m.opts = len(opts)
+	m.calls++
+	m.mu.Unlock()
+	if m.block {
+		<-ctx.Done()
+		close(m.cancelled)
+		return nil, ctx.Err()
+	}
+	return &models.ExecutionResults{
+		Success: true,
+		TestResults: []models.TestResult{
+			{TestCaseID: 1, ActualOutput: "3", Passed: true},
+			{TestCaseID: 2, ActualOutput: "12", Passed: true},
+		},
+	}, nil
+}
+
+func waitForStatus(t *testing.T, service svc.Service, id string, status string) *models.ExecutionJob {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := service.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s never reached status %s", id, status)
+	return nil
+}
+
+func TestExecutionJobService(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+
+	t.Run("SubmitRunsJobToCompletion", func(t *testing.T) {
+		service := svc.New(newMockJobRepository(), &mockExecutor{}, logger, 1)
+
+		job, err := service.Submit(context.Background(), "code", "go", 1)
+		if err != nil {
+			t.Fatalf("failed to submit job: %v", err)
+		}
+		if job.Status != models.JobStatusQueued {
+			t.Errorf("expected status %s, got %s", models.JobStatusQueued, job.Status)
+		}
+
+		finished := waitForStatus(t, service, job.ID, models.JobStatusFinished)
+		if !finished.Success {
+			t.Error("expected job to succeed")
+		}
+		if len(finished.TestResults) != 2 {
+			t.Errorf("expected 2 test results, got %d", len(finished.TestResults))
+		}
+		if finished.FinishedAt == nil {
+			t.Error("expected finished_at to be set")
+		}
+	})
+
+	t.Run("SubmitPassesOptions", func(t *testing.T) {
+		executor := &mockExecutor{}
+		service := svc.New(newMockJobRepository(), executor, logger, 1)
+
+		job, err := service.Submit(context.Background(), "code", "go", 0, code_executor.WithTenant(7), code_executor.WithVersion("1.21"))
+		if err != nil {
+			t.Fatalf("failed to submit job: %v", err)
+		}
+		waitForStatus(t, service, job.ID, models.JobStatusFinished)
+
+		if got := executor.receivedOptions(); got != 2 {
+			t.Errorf("expected the submitted options to reach the executor, got %d options", got)
+		}
+	})
+
+	t.Run("FailInterruptedJobs", func(t *testing.T) {
+		repo := newMockJobRepository()
+		for _, status := range []string{models.JobStatusQueued, models.JobStatusRunning, models.JobStatusFinished} {
+			repo.CreateJob(context.Background(), &models.ExecutionJob{ID: status, Status: status})
+		}
+		service := svc.New(repo, &mockExecutor{}, logger, 1)
+
+		n, err := service.FailInterrupted(context.Background())
+		if err != nil {
+			t.Fatalf("failed to fail interrupted jobs: %v", err)
+		}
+		if n != 2 {
+			t.Errorf("expected the queued and the running job to fail, got %d", n)
+		}
+		for id, want := range map[string]string{
+			models.JobStatusQueued:   models.JobStatusFailed,
+			models.JobStatusRunning:  models.JobStatusFailed,
+			models.JobStatusFinished: models.JobStatusFinished,
+		} {
+			job, _ := service.Get(context.Background(), id)
+			if job.Status != want {
+				t.Errorf("job %s: expected status %s, got %s", id, want, job.Status)
+			}
+		}
+	})
+
+	t.Run("SubmitRejectsUnsupportedLanguage", func(t *testing.T) {
+		service := svc.New(newMockJobRepository(), &mockExecutor{}, logger, 1)
+
+		_, err := service.Submit(context.Background(), "code", "cobol", 0)
+		if !errors.Is(err, code_executor.ErrUnsupportedLanguage) {
+			t.Fatalf("expected ErrUnsupportedLanguage, got %v", err)
+		}
+	})
+
+	t.Run("CancelStopsRunningJob", func(t *testing.T) {
+		executor := &mockExecutor{block: true, cancelled: make(chan struct{})}
+		service := svc.New(newMockJobRepository(), executor, logger, 1)
+
+		job, err := service.Submit(context.Background(), "code", "go", 1)
+		if err != nil {
+			t.Fatalf("failed to submit job: %v", err)
+		}
+		waitForStatus(t, service, job.ID, models.JobStatusRunning)
+
+		cancelled, err := service.Cancel(context.Background(), job.ID)
+		if err != nil {
+			t.Fatalf("failed to cancel job: %v", err)
+		}
+		if cancelled.Status != models.JobStatusCancelled {
+			t.Errorf("expected status %s, got %s", models.JobStatusCancelled, cancelled.Status)
+		}
+
+		select {
+		case <-executor.cancelled:
+		case <-time.After(2 * time.Second):
+			t.Fatal("executor context was not cancelled")
+		}
+
+		if _, err := service.Cancel(context.Background(), job.ID); !errors.Is(err, svc.ErrJobFinished) {
+			t.Errorf("expected ErrJobFinished when cancelling twice, got %v", err)
+		}
+	})
+
+	t.Run("JobStaysQueuedUntilWorkerIsFree", func(t *testing.T) {
+		executor := &mockExecutor{block: true, cancelled: make(chan struct{})}
+		service := svc.New(newMockJobRepository(), executor, logger, 1)
+
+		first, err := service.Submit(context.Background(), "code", "go", 1)
+		if err != nil {
+			t.Fatalf("failed to submit job: %v", err)
+		}
+		waitForStatus(t, service, first.ID, models.JobStatusRunning)
+
+		second, err := service.Submit(context.Background(), "code", "go", 1)
+		if err != nil {
+			t.Fatalf("failed to submit job: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if job, _ := service.Get(context.Background(), second.ID); job.Status != models.JobStatusQueued || job.StartedAt != nil {
+			t.Errorf("expected the second job to wait in the queue, got status %s", job.Status)
+		}
+
+		cancelled, err := service.Cancel(context.Background(), second.ID)
+		if err != nil {
+			t.Fatalf("failed to cancel queued job: %v", err)
+		}
+		if cancelled.Status != models.JobStatusCancelled {
+			t.Errorf("expected status %s, got %s", models.JobStatusCancelled, cancelled.Status)
+		}
+		if _, err := service.Cancel(context.Background(), first.ID); err != nil {
+			t.Fatalf("failed to cancel running job: %v", err)
+		}
+		<-executor.cancelled
+
+		time.Sleep(50 * time.Millisecond)
+		if got := executor.executions(); got != 1 {
+			t.Errorf("expected the cancelled queued job never to run, got %d executions", got)
+		}
+		if job, _ := service.Get(context.Background(), second.ID); job.Status != models.JobStatusCancelled {
+			t.Errorf("expected the queued job to stay cancelled, got %s", job.Status)
+		}
+	})
+
+	t.Run("CancelKeepsFinishedResult", func(t *testing.T) {
+		repo := newMockJobRepository()
+		service := svc.New(staleJobRepository{repo}, &mockExecutor{}, logger, 1)
+
+		job, err := service.Submit(context.Background(), "code", "go", 1)
+		if err != nil {
+			t.Fatalf("failed to submit job: %v", err)
+		}
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			stored, _ := repo.GetJobByID(context.Background(), job.ID)
+			if stored.Status == models.JobStatusFinished {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("job never finished")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if _, err := service.Cancel(context.Background(), job.ID); !errors.Is(err, svc.ErrJobFinished) {
+			t.Errorf("expected ErrJobFinished for a job that finished meanwhile, got %v", err)
+		}
+		stored, _ := repo.GetJobByID(context.Background(), job.ID)
+		if stored.Status != models.JobStatusFinished || !stored.Success || len(stored.TestResults) != 2 {
+			t.Errorf("expected the finished job to keep its result, got %+v", stored)
+		}
+	})
+
+	t.Run("GetUnknownJob", func(t *testing.T) {
+		service := svc.New(newMockJobRepository(), &mockExecutor{}, logger, 1)
+
+		if _, err := service.Get(context.Background(), "missing"); !errors.Is(err, svc.ErrJobNotFound) {
+			t.Fatalf("expected ErrJobNotFound, got %v", err)
+		}
+	})
+}